@@ -1,7 +1,10 @@
 package optruck
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/yammerjp/optruck/internal/interactive"
 	"github.com/yammerjp/optruck/pkg/actions"
@@ -11,35 +14,70 @@ import (
 	"github.com/yammerjp/optruck/pkg/output"
 )
 
-func (cli *CLI) buildAction(confirmation func() error) (actions.Action, error) {
+func (cli *CLI) buildAction(ctx context.Context, confirmation func() error, revertConfirmation func(message string) (bool, error)) (actions.Action, error) {
 	ds, err := cli.buildDataSource()
 	if err != nil {
 		return nil, err
 	}
 
-	dest, err := cli.buildDest()
+	dests, err := cli.buildDests()
 	if err != nil {
 		return nil, err
 	}
 
-	opItemClient, err := cli.buildOpItemClient(true)
+	if len(cli.Vaults) > 0 {
+		if cli.Parallel < 1 {
+			return nil, fmt.Errorf("--parallel must be at least 1, got %d", cli.Parallel)
+		}
+		opItemClients := make([]op.ItemClient, 0, len(cli.Vaults))
+		for _, vault := range cli.Vaults {
+			cli.Vault = vault
+			opItemClient, err := cli.buildOpItemClient(ctx, true)
+			if err != nil {
+				return nil, err
+			}
+			opItemClients = append(opItemClients, *opItemClient)
+		}
+		cli.Vault = ""
+		return &actions.MultiVaultMirrorConfig{
+			OpItemClients:      opItemClients,
+			DataSource:         ds,
+			Dests:              dests,
+			Force:              cli.Force,
+			Overwrite:          cli.Overwrite,
+			Confirmation:       confirmation,
+			RevertConfirmation: revertConfirmation,
+			Out:                os.Stderr,
+			Parallel:           cli.Parallel,
+			FailFast:           cli.FailFast,
+		}, nil
+	}
+
+	opItemClient, err := cli.buildOpItemClient(ctx, true)
 	if err != nil {
 		return nil, err
 	}
 
 	return &actions.MirrorConfig{
-		OpItemClient: *opItemClient,
-		DataSource:   ds,
-		Dest:         dest,
-		Overwrite:    cli.Overwrite,
-		Confirmation: confirmation,
+		OpItemClient:       *opItemClient,
+		DataSource:         ds,
+		Dests:              dests,
+		Force:              cli.Force,
+		Overwrite:          cli.Overwrite,
+		Confirmation:       confirmation,
+		RevertConfirmation: revertConfirmation,
+		Out:                os.Stderr,
 	}, nil
 }
 
-func (cli *CLI) buildOpItemClient(strict bool) (*op.ItemClient, error) {
+func (cli *CLI) buildOpItemClient(ctx context.Context, strict bool) (*op.ItemClient, error) {
 	if strict {
-		if cli.Account == "" {
-			accounts, err := op.NewExecutableClient().ListAccounts()
+		if op.IsServiceAccount() {
+			if err := cli.checkServiceAccountTarget(); err != nil {
+				return nil, err
+			}
+		} else if cli.Account == "" {
+			accounts, err := op.NewExecutableClient(op.WithExecutor(cli.executor)).ListAccounts(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list accounts: %w. Please check your 1Password configuration and try again.", err)
 			}
@@ -49,7 +87,7 @@ func (cli *CLI) buildOpItemClient(strict bool) (*op.ItemClient, error) {
 			cli.Account = accounts[0].URL
 		}
 		if cli.Vault == "" {
-			vaults, err := op.NewAccountClient(cli.Account).ListVaults()
+			vaults, err := op.NewAccountClient(cli.Account, op.WithExecutor(cli.executor)).ListVaults(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list vaults: %w. Please check your 1Password configuration and try again.", err)
 			}
@@ -63,7 +101,19 @@ func (cli *CLI) buildOpItemClient(strict bool) (*op.ItemClient, error) {
 		}
 	}
 
-	return op.NewItemClient(cli.Account, cli.Vault, cli.Item), nil
+	return op.NewItemClient(cli.Account, cli.Vault, cli.Item, op.WithExecutor(cli.executor)), nil
+}
+
+// checkServiceAccountTarget replaces the account and vault discovery for a
+// service account, which is bound to its account and may not see every vault.
+func (cli *CLI) checkServiceAccountTarget() error {
+	if cli.Account != "" {
+		return fmt.Errorf("--account cannot be used with %s, the service account belongs to its own account", op.ServiceAccountTokenEnv)
+	}
+	if cli.Vault == "" && len(cli.Vaults) == 0 {
+		return fmt.Errorf("%s is set, please specify the vault with --vault option", op.ServiceAccountTokenEnv)
+	}
+	return nil
 }
 
 func (cli *CLI) buildDataSource() (datasources.Source, error) {
@@ -74,28 +124,261 @@ func (cli *CLI) buildDataSource() (datasources.Source, error) {
 		return &datasources.K8sSecretSource{
 			Namespace:  cli.K8sNamespace,
 			SecretName: cli.K8sSecret,
-			Client:     kube.NewClient(),
+			Client:     kube.NewClient(kube.WithExecutor(cli.executor)),
 		}, nil
 	}
+	if cli.ComposeFile != "" {
+		if cli.ComposeService == "" {
+			return nil, fmt.Errorf("--compose-file requires --compose-service to select the service to read secrets from")
+		}
+		return &datasources.ComposeSource{
+			Path:    cli.ComposeFile,
+			Service: cli.ComposeService,
+		}, nil
+	}
+	if cli.ShellFile != "" {
+		return &datasources.ShellScriptSource{Path: cli.ShellFile}, nil
+	}
+	if cli.SystemdEnvFile != "" {
+		return &datasources.SystemdEnvFileSource{Path: cli.SystemdEnvFile}, nil
+	}
 	if cli.EnvFile == "" {
 		cli.EnvFile = interactive.DefaultEnvFilePath
 	}
 	return &datasources.EnvFileSource{Path: cli.EnvFile}, nil
 }
 
-func (cli *CLI) buildDest() (output.Dest, error) {
-	if cli.Output == "" {
-		cli.Output = interactive.DefaultOutputPath(cli.K8sSecret)
+// buildDests pairs each --format with the --output at the same position, and
+// writes the formats without one to their default paths.
+func (cli *CLI) buildDests() ([]output.Dest, error) {
+	formats, err := cli.resolveFormats()
+	if err != nil {
+		return nil, err
 	}
-	if cli.K8sSecret != "" {
+	if len(cli.Output) > len(formats) {
+		return nil, fmt.Errorf("more --output paths (%d) than formats (%d), please give a --format for each --output", len(cli.Output), len(formats))
+	}
+	if len(cli.Vaults) > 0 && cli.VaultVar == "" {
+		return nil, fmt.Errorf("--vaults requires --vault-var to write one template for all the vaults, e.g. --vault-var APP_ENV")
+	}
+	refOptions, err := op.ParseRefOptions(cli.RefStyle, cli.VaultVar)
+	if err != nil {
+		return nil, err
+	}
+
+	dests := make([]output.Dest, 0, len(formats))
+	paths := make(map[string]bool, len(formats))
+	for i, format := range formats {
+		path := ""
+		if i < len(cli.Output) {
+			path = cli.Output[i]
+		}
+		if path == "" {
+			path = cli.defaultOutputPath(format)
+		}
+		if paths[path] {
+			if path == output.StdoutPath {
+				return nil, fmt.Errorf("only one template can be written to stdout")
+			}
+			return nil, fmt.Errorf("more than one template would be written to %s, please give each --format its own --output", path)
+		}
+		paths[path] = true
+
+		dest, err := cli.buildDest(format, path, refOptions)
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, dest)
+	}
+	return dests, nil
+}
+
+func (cli *CLI) buildDest(format, path string, refOptions op.RefOptions) (output.Dest, error) {
+	if cli.Template != "" {
+		return &output.UserTemplateDest{
+			Path:         path,
+			TemplatePath: cli.Template,
+			Source:       cli.sourceMetadata(),
+			RefOptions:   refOptions,
+		}, nil
+	}
+	switch format {
+	case FormatK8s:
 		return &output.K8sSecretTemplateDest{
-			Path:       cli.Output,
+			Path:       path,
 			Namespace:  cli.K8sNamespace,
 			SecretName: cli.K8sSecret,
+			RefOptions: refOptions,
+		}, nil
+	case FormatOnePasswordItem:
+		return &output.OnePasswordItemDest{
+			Path:       path,
+			Namespace:  cli.K8sNamespace,
+			SecretName: cli.K8sSecret,
+			RefOptions: refOptions,
+		}, nil
+	case FormatExternalSecret:
+		return cli.buildExternalSecretDest(path, refOptions)
+	case FormatHelmValues:
+		if _, err := output.ParseHelmKeyPath(cli.HelmKeyPath); err != nil {
+			return nil, err
+		}
+		return &output.HelmValuesDest{
+			Path:       path,
+			KeyPath:    cli.HelmKeyPath,
+			RefOptions: refOptions,
+		}, nil
+	case FormatKustomize:
+		envFile := cli.KustomizeEnvFile
+		if envFile == "" {
+			envFile = output.DefaultKustomizeEnvFile(cli.K8sSecret)
+		}
+		return &output.KustomizeDest{
+			Path:       path,
+			Namespace:  cli.K8sNamespace,
+			SecretName: cli.K8sSecret,
+			EnvFile:    envFile,
+			RefOptions: refOptions,
+		}, nil
+	case FormatTerraform:
+		if refOptions.VaultVar != "" {
+			return nil, output.ErrTerraformVaultVar
+		}
+		return &output.TerraformDest{
+			Path:       path,
+			RefOptions: refOptions,
+		}, nil
+	case FormatGitHubActions:
+		return &output.GitHubActionsDest{
+			Path:       path,
+			RefOptions: refOptions,
+		}, nil
+	case FormatGitLabCI:
+		return &output.GitLabCIDest{
+			Path:       path,
+			RefOptions: refOptions,
+		}, nil
+	case FormatAnsible:
+		return cli.buildAnsibleVarsDest(path, refOptions)
+	case FormatAVP, FormatAVPEnv:
+		if refOptions.NeedsNameReference() {
+			return nil, fmt.Errorf("--format %s references the item by IDs, --ref-style name and --vault-var cannot be used", format)
+		}
+		if format == FormatAVPEnv {
+			return &output.AVPEnvDest{Path: path}, nil
+		}
+		return &output.AVPSecretDest{
+			Path:       path,
+			Namespace:  cli.K8sNamespace,
+			SecretName: cli.K8sSecret,
+		}, nil
+	case FormatCompose:
+		return &output.ComposeDest{
+			Path:       path,
+			Service:    cli.ComposeService,
+			EnvFile:    cli.ComposeEnvFile,
+			RefOptions: refOptions,
+		}, nil
+	case FormatShell:
+		return &output.ShellDest{
+			Path:       path,
+			RefOptions: refOptions,
+		}, nil
+	case FormatSystemd:
+		return &output.SystemdDest{
+			Path:       path,
+			RefOptions: refOptions,
+		}, nil
+	case FormatOpRunEnv:
+		return &output.OpRunEnvDest{
+			Path:       path,
+			RefOptions: refOptions,
+		}, nil
+	default:
+		return &output.EnvTemplateDest{
+			Path:       path,
+			RefOptions: refOptions,
 		}, nil
 	}
+}
 
-	return &output.EnvTemplateDest{
-		Path: cli.Output,
+func (cli *CLI) buildExternalSecretDest(path string, refOptions op.RefOptions) (*output.ExternalSecretDest, error) {
+	dest := &output.ExternalSecretDest{
+		RefOptions:      refOptions,
+		Path:            path,
+		Namespace:       cli.K8sNamespace,
+		SecretName:      cli.K8sSecret,
+		StoreName:       cli.ESOStore,
+		StoreKind:       cli.ESOStoreKind,
+		RefreshInterval: cli.ESORefreshInterval,
+	}
+	if dest.StoreName == "" {
+		dest.StoreName = output.DefaultExternalSecretStoreName
+	}
+	switch dest.StoreKind {
+	case "":
+		dest.StoreKind = output.DefaultExternalSecretStoreKind
+	case "SecretStore", "ClusterSecretStore":
+	default:
+		return nil, fmt.Errorf("invalid store kind: %s, must be SecretStore or ClusterSecretStore", dest.StoreKind)
+	}
+	if dest.RefreshInterval == "" {
+		dest.RefreshInterval = output.DefaultExternalSecretRefreshInterval
+	} else if _, err := time.ParseDuration(dest.RefreshInterval); err != nil {
+		return nil, fmt.Errorf("invalid refresh interval: %s, use a duration like '1h' or '15m'", dest.RefreshInterval)
+	}
+	return dest, nil
+}
+
+func (cli *CLI) buildAnsibleVarsDest(path string, refOptions op.RefOptions) (*output.AnsibleVarsDest, error) {
+	switch cli.AnsibleLookup {
+	case "", output.AnsibleLookupOnePassword, output.AnsibleLookupOpRead:
+	default:
+		return nil, fmt.Errorf("invalid ansible lookup: %s, must be %s or %s", cli.AnsibleLookup, output.AnsibleLookupOnePassword, output.AnsibleLookupOpRead)
+	}
+	switch cli.AnsibleVarCase {
+	case "", output.AnsibleVarCaseLower, output.AnsibleVarCaseKeep:
+	default:
+		return nil, fmt.Errorf("invalid ansible variable case: %s, must be %s or %s", cli.AnsibleVarCase, output.AnsibleVarCaseLower, output.AnsibleVarCaseKeep)
+	}
+	return &output.AnsibleVarsDest{
+		Path:       path,
+		Lookup:     cli.AnsibleLookup,
+		VarPrefix:  cli.AnsibleVarPrefix,
+		VarCase:    cli.AnsibleVarCase,
+		RefOptions: refOptions,
 	}, nil
 }
+
+func (cli *CLI) sourceMetadata() output.SourceMetadata {
+	if cli.K8sSecret != "" {
+		return output.SourceMetadata{
+			Type:       "k8s-secret",
+			Namespace:  cli.K8sNamespace,
+			SecretName: cli.K8sSecret,
+		}
+	}
+	if cli.ComposeFile != "" {
+		return output.SourceMetadata{
+			Type:    "compose",
+			Path:    cli.ComposeFile,
+			Service: cli.ComposeService,
+		}
+	}
+	if cli.ShellFile != "" {
+		return output.SourceMetadata{
+			Type: "shell",
+			Path: cli.ShellFile,
+		}
+	}
+	if cli.SystemdEnvFile != "" {
+		return output.SourceMetadata{
+			Type: "systemd",
+			Path: cli.SystemdEnvFile,
+		}
+	}
+	return output.SourceMetadata{
+		Type: "env-file",
+		Path: cli.EnvFile,
+	}
+}
@@ -0,0 +1,139 @@
+package optruck
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+)
+
+func TestBuildDests(t *testing.T) {
+	tests := []struct {
+		name      string
+		cli       *CLI
+		wantPaths []string
+		wantErr   bool
+	}{
+		{
+			name:      "default format and path",
+			cli:       &CLI{},
+			wantPaths: []string{".env.1password"},
+		},
+		{
+			name:      "stdout",
+			cli:       &CLI{OutputOptions: OutputOptions{Output: []string{output.StdoutPath}}},
+			wantPaths: []string{output.StdoutPath},
+		},
+		{
+			name: "formats paired with outputs by position",
+			cli: &CLI{
+				K8sSecret: "my-secret",
+				OutputOptions: OutputOptions{
+					Format: []string{FormatK8s, FormatOpRunEnv, FormatGitHubActions},
+					Output: []string{"-", "app.env"},
+				},
+			},
+			wantPaths: []string{output.StdoutPath, "app.env", output.DefaultGitHubActionsOutputPath},
+		},
+		{
+			name:    "more outputs than formats",
+			cli:     &CLI{OutputOptions: OutputOptions{Format: []string{FormatEnv}, Output: []string{"a.env", "b.env"}}},
+			wantErr: true,
+		},
+		{
+			name:    "two formats to the same default path",
+			cli:     &CLI{OutputOptions: OutputOptions{Format: []string{FormatEnv, FormatOpRunEnv}}},
+			wantErr: true,
+		},
+		{
+			name:    "two formats to stdout",
+			cli:     &CLI{OutputOptions: OutputOptions{Format: []string{FormatEnv, FormatOpRunEnv}, Output: []string{"-", "-"}}},
+			wantErr: true,
+		},
+		{
+			name:      "external-secret shared by several vaults",
+			cli:       &CLI{K8sSecret: "my-secret", Vaults: []string{"dev", "prod"}, OutputOptions: OutputOptions{VaultVar: "APP_ENV", Format: []string{FormatExternalSecret}}},
+			wantPaths: []string{output.DefaultExternalSecretOutputPath("my-secret")},
+		},
+		{
+			name:      "external-secret ignores the ref style",
+			cli:       &CLI{K8sSecret: "my-secret", OutputOptions: OutputOptions{RefStyle: "name", Format: []string{FormatExternalSecret}}},
+			wantPaths: []string{output.DefaultExternalSecretOutputPath("my-secret")},
+		},
+		{
+			name:    "invalid format among others",
+			cli:     &CLI{OutputOptions: OutputOptions{Format: []string{FormatEnv, "dotenv"}, Output: []string{"a.env", "b.env"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dests, err := tt.cli.buildDests()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			paths := make([]string, 0, len(dests))
+			for _, dest := range dests {
+				paths = append(paths, dest.GetPath())
+			}
+			if !slices.Equal(paths, tt.wantPaths) {
+				t.Errorf("paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestBuildOpItemClientWithServiceAccount(t *testing.T) {
+	t.Setenv(op.ServiceAccountTokenEnv, "ops_test")
+
+	tests := []struct {
+		name    string
+		cli     *CLI
+		wantErr bool
+	}{
+		{
+			name: "vault given",
+			cli:  &CLI{Vault: "ci", Item: "my-app"},
+		},
+		{
+			name:    "vault missing",
+			cli:     &CLI{Item: "my-app"},
+			wantErr: true,
+		},
+		{
+			name:    "account given",
+			cli:     &CLI{Account: "my.1password.com", Vault: "ci", Item: "my-app"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// no op command is run to discover the account or the vault
+			tt.cli.executor = utilExec.NewExecutor(NewMockExec())
+			client, err := tt.cli.buildOpItemClient(context.Background(), true)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if client.Account != "" || client.Vault != "ci" {
+				t.Errorf("client = %+v, want no account and vault ci", client)
+			}
+		})
+	}
+}
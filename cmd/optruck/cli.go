@@ -1,26 +1,94 @@
 package optruck
 
+import (
+	"time"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+)
+
 type InteractiveFlag bool
 
+type Root struct {
+	Mirror   CLI         `cmd:"" default:"withargs" help:"Upload secrets to 1Password and generate a restoration template."`
+	History  HistoryCmd  `cmd:"" help:"List the versions of a 1Password item uploaded by optruck."`
+	Rollback RollbackCmd `cmd:"" help:"Restore a previous version of a 1Password item and regenerate the template."`
+
+	// General Options
+	Version  VersionFlag   `short:"v" help:"Show the version of optruck."`
+	LogLevel string        `name:"log-level" help:"Set the log level (debug|info|warn|error|none)." enum:"debug,info,warn,error,none" default:"none"`
+	Timeout  time.Duration `name:"timeout" default:"2m" help:"Time to wait for each op or kubectl command, or 0 to wait forever (e.g., '30s')."`
+}
+
 type CLI struct {
 	// max length is 100
 	Item string `arg:"" optional:"" name:"item" help:"Name or ID of the 1Password item to process."`
 
 	// Target Options
-	Account   string `name:"account" help:"1Password account (e.g., 'my.1password.com' or 'my.1password.example.com')."`
-	Vault     string `name:"vault" help:"1Password Vault Name or ID (e.g., 'Development' or 'abcd1234efgh5678')."`
-	Overwrite bool   `name:"overwrite" help:"Overwrite the existing 1Password item if it exists."`
+	Account   string   `name:"account" help:"1Password account (e.g., 'my.1password.com' or 'my.1password.example.com')."`
+	Vault     string   `name:"vault" help:"1Password Vault Name or ID (e.g., 'Development' or 'abcd1234efgh5678')." xor:"target-vault"`
+	Vaults    []string `name:"vaults" sep:"," help:"Comma-separated 1Password Vault Names to upload the same secrets to (e.g., 'dev,staging,prod'). Requires --vault-var." xor:"target-vault"`
+	Parallel  int      `name:"parallel" default:"1" help:"With --vaults, number of vaults to upload to at the same time."`
+	FailFast  bool     `name:"fail-fast" help:"With --vaults, stop uploading to the other vaults once one fails."`
+	Overwrite bool     `name:"overwrite" help:"Overwrite the existing 1Password item if it exists."`
 
 	// Data Source Options
-	EnvFile      string `name:"env-file" type:"existingfile" optional:"" help:"Path to the .env file containing secrets.(default: '.env')" xor:"source-type,source-k8s"`
-	K8sSecret    string `name:"k8s-secret" optional:"" help:"Name of the Kubernetes Secret to fetch secrets from." xor:"source-type"`
-	K8sNamespace string `name:"k8s-namespace" optional:"" help:"Kubernetes namespace.(default: 'default')" xor:"source-k8s"`
+	EnvFile        string `name:"env-file" type:"existingfile" optional:"" help:"Path to the .env file containing secrets.(default: '.env')" xor:"source-type,source-k8s"`
+	K8sSecret      string `name:"k8s-secret" optional:"" help:"Name of the Kubernetes Secret to fetch secrets from." xor:"source-type"`
+	K8sNamespace   string `name:"k8s-namespace" optional:"" help:"Kubernetes namespace.(default: 'default')" xor:"source-k8s"`
+	ComposeFile    string `name:"compose-file" type:"existingfile" optional:"" help:"Path to the docker compose file to read the service's environment and env_file from." xor:"source-type,source-k8s"`
+	ShellFile      string `name:"shell-file" type:"existingfile" optional:"" help:"Path to the shell script of 'export KEY=value' lines containing secrets." xor:"source-type,source-k8s"`
+	SystemdEnvFile string `name:"systemd-env-file" type:"existingfile" optional:"" help:"Path to the systemd EnvironmentFile containing secrets." xor:"source-type,source-k8s"`
+	ComposeService string `name:"compose-service" optional:"" help:"Name of the docker compose service to read secrets from, or to write the override for with --format compose."`
 
 	// Output Options
-	Output string `name:"output" type:"path" help:"Path to save the restoration template file. (default: '.env.1password' if format is env, otherwise '<name>-secret.yaml.1password' if format is k8s)"` // Don't set kong's default value
+	OutputOptions `embed:""`
 
 	// General Options
-	Version     VersionFlag     `short:"v" help:"Show the version of optruck."`
-	LogLevel    string          `name:"log-level" help:"Set the log level (debug|info|warn|error|none)." enum:"debug,info,warn,error,none" default:"none"`
 	Interactive InteractiveFlag `name:"interactive" help:"Enable interactive mode for selecting the item, account, and vault." short:"i"`
+
+	// executor runs op and kubectl, on the host unless replaced in tests
+	executor *utilExec.Executor
+}
+
+type HistoryCmd struct {
+	Item string `arg:"" name:"item" help:"Name or ID of the 1Password item."`
+
+	// Target Options
+	Account string `name:"account" help:"1Password account (e.g., 'my.1password.com' or 'my.1password.example.com')."`
+	Vault   string `name:"vault" help:"1Password Vault Name or ID (e.g., 'Development' or 'abcd1234efgh5678')."`
+}
+
+type RollbackCmd struct {
+	Item      string `arg:"" name:"item" help:"Name or ID of the 1Password item."`
+	ToVersion int    `name:"to-version" required:"" help:"Version of the item to restore (see 'optruck history <item>')."`
+
+	// Target Options
+	Account string `name:"account" help:"1Password account (e.g., 'my.1password.com' or 'my.1password.example.com')."`
+	Vault   string `name:"vault" help:"1Password Vault Name or ID (e.g., 'Development' or 'abcd1234efgh5678')."`
+
+	// Output Options
+	K8sSecret      string `name:"k8s-secret" optional:"" help:"Name of the Kubernetes Secret the template restores to."`
+	K8sNamespace   string `name:"k8s-namespace" optional:"" help:"Kubernetes namespace.(default: 'default')"`
+	ComposeService string `name:"compose-service" optional:"" help:"Name of the docker compose service the override is written for with --format compose."`
+	OutputOptions  `embed:""`
+}
+
+// OutputOptions select the templates to write. The mirror and rollback
+// commands share them, so that rollback regenerates the same templates.
+type OutputOptions struct {
+	Output             []string `name:"output" type:"path" sep:"none" help:"Path to save the restoration template file, or '-' for stdout. Repeat it with --format to write several templates. (default: '.env.1password' if format is env, otherwise '<name>-secret.yaml.1password' if format is k8s)"` // Don't set kong's default value
+	Format             []string `name:"format" sep:"none" help:"Format of the template (env|k8s|op-run-env|compose|shell|systemd|onepassword-item|external-secret|helm-values|kustomize|terraform|github-actions|gitlab-ci|ansible|avp|avp-env). Repeat it to write several templates. (default: the format of the data source or 'k8s' if --k8s-secret is set, otherwise 'env')"`
+	RefStyle           string   `name:"ref-style" enum:"id,name" default:"id" help:"Reference the vault and item by 'id' (stable) or 'name' (readable) in the template."`
+	VaultVar           string   `name:"vault-var" help:"Reference the vault as '${<name>}' so one template resolves against the same item in several vaults (e.g., 'APP_ENV')."`
+	Template           string   `name:"template" type:"existingfile" help:"Path to a Go text/template file to render instead of the built-in template."`
+	ComposeEnvFile     string   `name:"compose-env-file" help:"With --format compose, point the service's env_file at this path instead of setting its environment."`
+	HelmKeyPath        string   `name:"helm-key-path" help:"With --format helm-values, dot-separated key to nest the values under (e.g., 'app.secrets')."`
+	KustomizeEnvFile   string   `name:"kustomize-env-file" help:"With --format kustomize, env file the secretGenerator reads. (default: '<name>.env')"`
+	AnsibleLookup      string   `name:"ansible-lookup" help:"With --format ansible, lookup reading the fields (onepassword|op-read). (default: 'onepassword')"`
+	AnsibleVarPrefix   string   `name:"ansible-var-prefix" help:"With --format ansible, prefix of the variable names (e.g., 'my_app_')."`
+	AnsibleVarCase     string   `name:"ansible-var-case" help:"With --format ansible, case of the variable names (lower|keep). (default: 'lower')"`
+	ESOStore           string   `name:"eso-store" help:"With --format external-secret, name of the 1Password SecretStore. (default: 'onepassword')"`
+	ESOStoreKind       string   `name:"eso-store-kind" help:"With --format external-secret, kind of the store (SecretStore|ClusterSecretStore). (default: 'SecretStore')"`
+	ESORefreshInterval string   `name:"eso-refresh-interval" help:"With --format external-secret, how often the Secret is synced from 1Password. (default: '1h')"`
+	Force              bool     `name:"force" help:"Replace existing template files that differ from the new templates."`
 }
@@ -0,0 +1,41 @@
+package optruck
+
+import (
+	"errors"
+
+	"github.com/yammerjp/optruck/pkg/kube"
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// exitFailure is the exit code of a failure without a code of its own.
+const exitFailure = 1
+
+// failures are the failures a user can act on, each with its own exit code
+// so that scripts can tell them apart. The codes must not change.
+var failures = []struct {
+	err  error
+	code int
+	hint string
+}{
+	{op.ErrNotSignedIn, 3, "Sign in with `eval $(op signin)`, or set OP_SERVICE_ACCOUNT_TOKEN, and try again."},
+	{op.ErrAccountNotFound, 4, "Check the accounts with `op account list` and pass one with --account."},
+	{op.ErrVaultNotFound, 5, "Check the vaults with `op vault list` and pass the name or ID of one with --vault."},
+	{op.ErrItemNotFound, 6, "Check the items with `op item list --vault <vault>` and pass the name or ID of one."},
+	{op.ErrPermissionDenied, 7, "Ask an owner of the vault for access, or choose a vault you can write to."},
+	{op.ErrRateLimited, 8, "1Password is limiting the requests. Wait a minute and try again."},
+	{kube.ErrNotFound, 9, "Check the secrets with `kubectl get secrets -n <namespace>` and pass one with --k8s-secret and --k8s-namespace."},
+	{kube.ErrForbidden, 10, "Check that the current kubectl context can read secrets in the namespace."},
+	{op.ErrNetwork, 11, "Check the network connection to 1Password and try again."},
+	{op.ErrMoreThanOneItemFound, 12, "Pass the ID of the item instead of its name."},
+}
+
+// describeError returns the exit code for err and a hint on how to fix it,
+// which is empty for a failure without a code of its own.
+func describeError(err error) (int, string) {
+	for _, f := range failures {
+		if errors.Is(err, f.err) {
+			return f.code, f.hint
+		}
+	}
+	return exitFailure, ""
+}
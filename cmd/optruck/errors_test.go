@@ -0,0 +1,62 @@
+package optruck
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+	"github.com/yammerjp/optruck/pkg/kube"
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantHint bool
+	}{
+		{
+			name: "not signed in to 1Password",
+			err: fmt.Errorf("failed to filter items: %w", &utilExec.CommandError{
+				Bin:    "op",
+				Args:   []string{"item", "list"},
+				Stderr: "[ERROR] You are not currently signed in.",
+				Kind:   op.ErrNotSignedIn,
+				Err:    errors.New("exit status 1"),
+			}),
+			wantCode: 3,
+			wantHint: true,
+		},
+		{
+			name:     "vault not found",
+			err:      fmt.Errorf("failed to upload secrets to vault dev: %w", op.ErrVaultNotFound),
+			wantCode: 5,
+			wantHint: true,
+		},
+		{
+			name:     "kubernetes secret not found",
+			err:      fmt.Errorf("failed to fetch secrets from Kubernetes: %w", kube.ErrNotFound),
+			wantCode: 9,
+			wantHint: true,
+		},
+		{
+			name:     "other failure",
+			err:      errors.New("failed to parse the shell script"),
+			wantCode: exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, hint := describeError(tt.err)
+			if code != tt.wantCode {
+				t.Errorf("code = %d, want %d", code, tt.wantCode)
+			}
+			if (hint != "") != tt.wantHint {
+				t.Errorf("hint = %q, want a hint: %v", hint, tt.wantHint)
+			}
+		})
+	}
+}
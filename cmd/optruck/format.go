@@ -0,0 +1,116 @@
+package optruck
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/yammerjp/optruck/internal/interactive"
+	"github.com/yammerjp/optruck/pkg/output"
+)
+
+const (
+	FormatEnv      = "env"
+	FormatK8s      = "k8s"
+	FormatOpRunEnv = "op-run-env"
+	FormatCompose  = "compose"
+	FormatShell    = "shell"
+	FormatSystemd  = "systemd"
+
+	FormatOnePasswordItem = "onepassword-item"
+	FormatExternalSecret  = "external-secret"
+	FormatHelmValues      = "helm-values"
+	FormatKustomize       = "kustomize"
+	FormatTerraform       = "terraform"
+	FormatGitHubActions   = "github-actions"
+	FormatGitLabCI        = "gitlab-ci"
+	FormatAnsible         = "ansible"
+	FormatAVP             = "avp"
+	FormatAVPEnv          = "avp-env"
+)
+
+var formats = []string{
+	FormatEnv, FormatK8s, FormatOpRunEnv, FormatCompose, FormatShell, FormatSystemd,
+	FormatOnePasswordItem, FormatExternalSecret, FormatHelmValues, FormatKustomize, FormatTerraform,
+	FormatGitHubActions, FormatGitLabCI, FormatAnsible, FormatAVP, FormatAVPEnv,
+}
+
+// secretFormats name the Kubernetes Secret by --k8s-secret.
+var secretFormats = []string{FormatK8s, FormatOnePasswordItem, FormatExternalSecret, FormatKustomize, FormatAVP}
+
+// resolveFormats returns the output formats in the order of --format,
+// defaulting to the one matching the data source.
+func (cli *CLI) resolveFormats() ([]string, error) {
+	if len(cli.Format) == 0 {
+		return []string{cli.defaultFormat()}, nil
+	}
+	if cli.Template != "" {
+		return nil, fmt.Errorf("--format and --template cannot be used together")
+	}
+	for _, format := range cli.Format {
+		if !slices.Contains(formats, format) {
+			return nil, fmt.Errorf("invalid format: %s, must be one of %s", format, strings.Join(formats, ", "))
+		}
+		if slices.Contains(secretFormats, format) && cli.K8sSecret == "" {
+			return nil, fmt.Errorf("--format %s requires --k8s-secret to name the Secret", format)
+		}
+		if format == FormatCompose && cli.ComposeService == "" {
+			return nil, fmt.Errorf("--format compose requires --compose-service to name the service")
+		}
+	}
+	return cli.Format, nil
+}
+
+func (cli *CLI) defaultFormat() string {
+	if cli.K8sSecret != "" {
+		return FormatK8s
+	}
+	if cli.ComposeFile != "" {
+		return FormatCompose
+	}
+	if cli.ShellFile != "" {
+		return FormatShell
+	}
+	if cli.SystemdEnvFile != "" {
+		return FormatSystemd
+	}
+	return FormatEnv
+}
+
+func (cli *CLI) defaultOutputPath(format string) string {
+	if cli.Template != "" {
+		return output.DefaultUserTemplateOutputPath(cli.Template)
+	}
+	switch format {
+	case FormatK8s:
+		return interactive.DefaultOutputPath(cli.K8sSecret)
+	case FormatCompose:
+		return output.DefaultComposeOutputPath(cli.ComposeEnvFile)
+	case FormatOnePasswordItem:
+		return output.DefaultOnePasswordItemOutputPath(cli.K8sSecret)
+	case FormatExternalSecret:
+		return output.DefaultExternalSecretOutputPath(cli.K8sSecret)
+	case FormatHelmValues:
+		return output.DefaultHelmValuesOutputPath
+	case FormatKustomize:
+		return output.DefaultKustomizeOutputPath
+	case FormatTerraform:
+		return output.DefaultTerraformOutputPath(cli.Item)
+	case FormatGitHubActions:
+		return output.DefaultGitHubActionsOutputPath
+	case FormatGitLabCI:
+		return output.DefaultGitLabCIOutputPath
+	case FormatAnsible:
+		return output.DefaultAnsibleOutputPath
+	case FormatAVP:
+		return output.DefaultAVPSecretOutputPath(cli.K8sSecret)
+	case FormatAVPEnv:
+		return output.DefaultAVPEnvOutputPath
+	case FormatShell:
+		return output.DefaultShellOutputPath
+	case FormatSystemd:
+		return output.DefaultSystemdOutputPath
+	default:
+		return interactive.DefaultOutputPath("")
+	}
+}
@@ -11,31 +11,111 @@ func helpPrinter(options kong.HelpOptions, ctx *kong.Context) error {
 
 Usage:
   optruck <item> [options]
+  optruck history <item> [options]
+  optruck rollback <item> --to-version <version> [options]
 
 Description:
   optruck helps you manage application secrets using 1Password. It can upload secrets from
   .env files (default) or Kubernetes Secrets to 1Password, and generate templates for
   restoring them later.
 
+Commands:
+  <item>                Upload secrets to 1Password and generate a restoration template (default).
+  history <item>        List the versions of the item with the changed field labels (values are masked).
+  rollback <item>       Restore the field values of a previous version and regenerate the template.
+
 Arguments:
   <item>                Name to save the secrets as in 1Password. Required unless --interactive is used.
 
 Target Options:
   --vault <value>       1Password Vault (e.g., "Development" or "abcd1234efgh5678").
   --account <value>     1Password account (e.g., "my.1password.com" or "my.1password.example.com").
+  --vaults <values>     Comma-separated vault names to upload the same secrets to (e.g., "dev,staging,prod").
+                        Requires --vault-var. Fails if the items end up with different fields.
+  --parallel <n>        With --vaults, number of vaults to upload to at the same time (default: 1).
+  --fail-fast           With --vaults, stop uploading to the other vaults once one fails.
   --overwrite           Overwrite the existing 1Password item if it exists.
 
 Data Source Options:
   --env-file <path>     Path to the .env file containing secrets (default: ".env").
   --k8s-secret <name>   Name of the Kubernetes Secret to fetch secrets from.
   --k8s-namespace <name> Kubernetes namespace for --k8s-secret (default: "default").
+  --compose-file <path> Path to the docker compose file to read secrets from.
+  --shell-file <path>   Path to a shell script of "export KEY=value" lines to read secrets from.
+  --systemd-env-file <path>
+                        Path to a systemd EnvironmentFile to read secrets from.
+  --compose-service <name>
+                        Service whose environment and env_file are read from --compose-file,
+                        or whose override is written with --format compose.
 
 Output Options:
-  --output <path>       Path to save the template file (default: ".env.1password" or "<secret-name>-secret.yaml.1password").
+  --output <path>       Path to save the template file, or "-" for stdout
+                        (default: ".env.1password" or "<secret-name>-secret.yaml.1password").
+  --format <format>     Format of the template (default: the format of the data source, otherwise "env").
+                        Repeat --format to write several templates in one run; the n-th --output
+                        is the path of the n-th --format, and the rest use their default paths.
+                        env         KEY={{op://...}} lines for "op inject"
+                        k8s         Kubernetes Secret manifest for "op inject"
+                        onepassword-item
+                                    OnePasswordItem for the 1Password Kubernetes Operator (needs --k8s-secret)
+                        external-secret
+                                    ExternalSecret for the External Secrets Operator (needs --k8s-secret)
+                        helm-values Helm values file for "op inject" before "helm -f"
+                        terraform   onepassword_item data source and locals for the 1Password provider
+                        github-actions
+                                    1password/load-secrets-action step mapping each key to its reference
+                        gitlab-ci   GitLab CI hidden job with the references as variables for "op run"
+                        ansible     group_vars/host_vars file looking up each field from 1Password
+                        avp         Secret with argocd-vault-plugin placeholders (needs --k8s-secret)
+                        avp-env     KEY=<path:vaults/<id>/items/<id>#KEY> lines for argocd-vault-plugin
+                        kustomize   kustomization.yaml with a secretGenerator reading an env file,
+                                    and the env file template (needs --k8s-secret)
+                        op-run-env  KEY=op://... lines for "op run --env-file", no plaintext on disk
+                        compose     docker compose override setting the service's environment
+                        shell       export KEY='{{op://...}}' lines to source after "op inject"
+                        systemd     KEY='{{op://...}}' lines for EnvironmentFile= after "op inject"
+  --compose-env-file <path>
+                        With --format compose, write an override pointing env_file at <path>
+                        and the template for <path> as "<path>.1password".
+  --ref-style <style>   Reference the vault and item by "id" (stable, default) or "name" (readable in code review).
+                        "name" requires unique vault and item names made of letters, digits, "-" and "_".
+  --vault-var <name>    Reference the vault as "${<name>}" (e.g., "op://${APP_ENV}/my-app/DB_PASSWORD"),
+                        so one template resolves against the same item in several vaults.
+  --template <path>     Render a Go text/template file instead of the built-in template
+                        (default output: the template name without ".tmpl" + ".1password").
+                        Data: .Account .Vault.Name/.ID .Item.Name/.ID .Fields (.Label .Ref .URI)
+                        .Source.Type/.Path/.Namespace/.SecretName/.Service
+                        .Dest.Path/.Basename .Header
+                        Functions: quote yaml base64 upper
+  --force               Replace existing template files that differ from the new templates.
+                        Without it, optruck prints the diff and fails before writing any template.
+  --helm-key-path <path>
+                        With --format helm-values, dot-separated key to nest the values under (e.g., "app.secrets").
+  --kustomize-env-file <path>
+                        With --format kustomize, env file the secretGenerator reads (default: "<secret-name>.env").
+  --ansible-lookup <lookup>
+                        With --format ansible, "onepassword" (community.general.onepassword, default)
+                        or "op-read" ("op read op://..." through the pipe lookup).
+  --ansible-var-prefix <prefix>
+                        With --format ansible, prefix of the variable names (e.g., "my_app_").
+  --ansible-var-case <case>
+                        With --format ansible, "lower" (default) or "keep" the case of the keys.
+  --eso-store <name>    With --format external-secret, name of the 1Password SecretStore (default: "onepassword").
+  --eso-store-kind <kind>
+                        SecretStore (default) or ClusterSecretStore.
+  --eso-refresh-interval <duration>
+                        How often the Secret is synced from 1Password (default: "1h").
+
+Rollback Options:
+  --to-version <version> Version to restore, as listed by "optruck history <item>".
+                        --output, --format, --ref-style, --vault-var, --template, --k8s-secret,
+                        --k8s-namespace, --compose-service, --compose-env-file, --helm-key-path,
+                        --kustomize-env-file, --ansible-*, --eso-* and --force select the template to regenerate.
 
 General Options:
   -i, --interactive     Enable interactive mode to select item, account, and vault.
   --log-level <level>   Set the log level (debug|info|warn|error|none). Defaults to "none".
+  --timeout <duration>  Time to wait for each op or kubectl command, or 0 to wait forever. Defaults to "2m".
   -h, --help           Show help for optruck.
   -v, --version        Show the version of optruck.
 
@@ -53,9 +133,56 @@ Examples:
   $ optruck MySecrets --k8s-secret my-secret --k8s-namespace my-namespace
   # -> Generates "my-secret-secret.yaml.1password"
 
+  # Run a command with the secrets without writing a plaintext .env
+  $ optruck MySecrets --format op-run-env
+  $ op run --env-file .env.1password -- npm start
+
+  # Write a Secret manifest to stdout and an env file for "op run" in one run
+  $ optruck MySecrets --k8s-secret my-secret --format k8s --output - --format op-run-env --output app.env
+
+  # Let the 1Password Operator manage a Kubernetes Secret
+  $ optruck MySecrets --k8s-secret my-secret --format onepassword-item
+  # -> Generates "my-secret-onepassworditem.yaml" to commit and apply
+
+  # Migrate a docker compose service
+  $ optruck my-app --compose-file compose.yaml --compose-service app
+  # -> Restore with "op inject -i compose.override.yaml.1password -o compose.override.yaml"
+
+  # Migrate a systemd EnvironmentFile
+  $ optruck my-daemon --systemd-env-file /etc/my-daemon/env --output my-daemon.env.1password
+  # -> Restore with "op inject -i my-daemon.env.1password -o my-daemon.env"
+
+  # One template for the same item in the dev, staging and prod vaults
+  $ optruck my-app --vaults dev,staging,prod --vault-var APP_ENV
+  # -> Restore with "APP_ENV=prod op inject -i .env.1password -o .env"
+
+  # Undo a mistaken --overwrite
+  $ optruck history MySecrets --vault MyVault
+  $ optruck rollback MySecrets --vault MyVault --to-version 3
+
 Notes:
   - op (1Password CLI) must be installed and configured.
+  - With OP_SERVICE_ACCOUNT_TOKEN set (e.g., in CI), optruck runs op as the service account:
+    --vault is required, --account cannot be used, and the restore commands omit --account.
   - When using Kubernetes options, ensure kubectl is configured properly.
+  - Templates are written to a temporary file and renamed into place, so a failure never leaves
+    a truncated template. New files get mode 0644; replaced files keep their mode.
+  - If the templates cannot be written after the upload, optruck archives the item it created,
+    or restores the previous fields of the item it overwrote (asking first in interactive mode).
+    Ctrl-C or SIGTERM stops the running op or kubectl command; a finished upload is reverted the same way.
+  - Before --overwrite updates an item, optruck saves its previous fields as an archived
+    item tagged "optruck-history/<item-id>" in the same vault.
+  - op calls that are rate limited or fail on the network are retried with backoff; a failed
+    create or edit is retried only once a read shows it did not take effect.
+
+Exit codes:
+  1  any other failure              6  1Password item not found
+  3  not signed in to 1Password     7  permission denied by 1Password
+  4  1Password account not found    8  rate limited by 1Password
+  5  1Password vault not found      9  Kubernetes namespace or secret not found
+                                    10 forbidden by Kubernetes
+                                    11 failed to reach 1Password
+                                    12 more than one 1Password item has the name
 `)
 
 	return nil
@@ -0,0 +1,25 @@
+package optruck
+
+import (
+	"context"
+	"os"
+
+	"github.com/yammerjp/optruck/pkg/actions"
+)
+
+func (cmd *HistoryCmd) Run(ctx context.Context) error {
+	target := CLI{
+		Item:    cmd.Item,
+		Account: cmd.Account,
+		Vault:   cmd.Vault,
+	}
+	opItemClient, err := target.buildOpItemClient(ctx, true)
+	if err != nil {
+		return err
+	}
+	action := &actions.HistoryConfig{
+		OpItemClient: *opItemClient,
+		Out:          os.Stdout,
+	}
+	return action.Run(ctx)
+}
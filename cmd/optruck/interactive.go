@@ -1,17 +1,20 @@
 package optruck
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"os"
 
 	"github.com/yammerjp/optruck/internal/interactive"
+	"github.com/yammerjp/optruck/pkg/op"
 )
 
-func (cli *CLI) SetOptionsInteractively(runner interactive.Runner) error {
-	if err := cli.setDataSourceInteractively(runner); err != nil {
+func (cli *CLI) SetOptionsInteractively(ctx context.Context, runner interactive.Runner) error {
+	if err := cli.setDataSourceInteractively(ctx, runner); err != nil {
 		return err
 	}
-	if err := cli.setTargetInteractively(runner); err != nil {
+	if err := cli.setTargetInteractively(ctx, runner); err != nil {
 		return err
 	}
 	if err := cli.setDestInteractively(runner); err != nil {
@@ -20,9 +23,9 @@ func (cli *CLI) SetOptionsInteractively(runner interactive.Runner) error {
 	return nil
 }
 
-func (cli *CLI) setDataSourceInteractively(runner interactive.Runner) error {
-	if cli.EnvFile != "" || cli.K8sSecret != "" {
-		slog.Debug("data source already set", "envFile", cli.EnvFile, "k8sSecret", cli.K8sSecret)
+func (cli *CLI) setDataSourceInteractively(ctx context.Context, runner interactive.Runner) error {
+	if cli.EnvFile != "" || cli.K8sSecret != "" || cli.ComposeFile != "" || cli.ShellFile != "" || cli.SystemdEnvFile != "" {
+		slog.Debug("data source already set", "envFile", cli.EnvFile, "k8sSecret", cli.K8sSecret, "composeFile", cli.ComposeFile, "shellFile", cli.ShellFile, "systemdEnvFile", cli.SystemdEnvFile)
 		// already set
 		return nil
 	}
@@ -41,14 +44,14 @@ func (cli *CLI) setDataSourceInteractively(runner interactive.Runner) error {
 	case interactive.DataSourceK8sSecret:
 		slog.Debug("setting k8s secret")
 		if cli.K8sNamespace == "" {
-			namespace, err := runner.SelectKubeNamespace()
+			namespace, err := runner.SelectKubeNamespace(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to select Kubernetes namespace: %w. Please select a valid namespace and try again.", err)
 			}
 			cli.K8sNamespace = namespace
 		}
 		if cli.K8sSecret == "" {
-			secret, err := runner.SelectKubeSecret(cli.K8sNamespace)
+			secret, err := runner.SelectKubeSecret(ctx, cli.K8sNamespace)
 			if err != nil {
 				return fmt.Errorf("failed to select Kubernetes secret: %w. Please select a valid secret and try again.", err)
 			}
@@ -60,17 +63,22 @@ func (cli *CLI) setDataSourceInteractively(runner interactive.Runner) error {
 	return nil
 }
 
-func (cli *CLI) setTargetInteractively(runner interactive.Runner) error {
-	if cli.Account == "" {
-		account, err := runner.SelectOpAccount()
+func (cli *CLI) setTargetInteractively(ctx context.Context, runner interactive.Runner) error {
+	if op.IsServiceAccount() {
+		// there is no account to select, nor vaults to list reliably
+		if err := cli.checkServiceAccountTarget(); err != nil {
+			return err
+		}
+	} else if cli.Account == "" {
+		account, err := runner.SelectOpAccount(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to select 1Password account: %w. Please select a valid account and try again.", err)
 		}
 		cli.Account = account
 	}
 
-	if cli.Vault == "" {
-		vault, err := runner.SelectOpVault(cli.Account)
+	if cli.Vault == "" && len(cli.Vaults) == 0 {
+		vault, err := runner.SelectOpVault(ctx, cli.Account)
 		if err != nil {
 			return fmt.Errorf("failed to select 1Password vault: %w. Please select a valid vault and try again.", err)
 		}
@@ -85,13 +93,13 @@ func (cli *CLI) setTargetInteractively(runner interactive.Runner) error {
 			cli.Overwrite = overwrite
 		}
 		if cli.Overwrite {
-			itemName, err := runner.SelectOpItemName(cli.Account, cli.Vault)
+			itemName, err := runner.SelectOpItemName(ctx, cli.Account, cli.Vault)
 			if err != nil {
 				return fmt.Errorf("failed to select 1Password item name: %w. Please select a valid item name and try again.", err)
 			}
 			cli.Item = itemName
 		} else {
-			itemName, err := runner.PromptOpItemName(cli.Account, cli.Vault, cli.K8sSecret)
+			itemName, err := runner.PromptOpItemName(ctx, cli.Account, cli.Vault, cli.K8sSecret)
 			if err != nil {
 				return fmt.Errorf("failed to prompt 1Password item name: %w. Please provide a valid item name and try again.", err)
 			}
@@ -102,14 +110,26 @@ func (cli *CLI) setTargetInteractively(runner interactive.Runner) error {
 }
 
 func (cli *CLI) setDestInteractively(runner interactive.Runner) error {
-	if cli.Output != "" {
+	if len(cli.Output) > 0 {
 		// already set
 		return nil
 	}
-	outputPath, err := runner.PromptOutputPath(cli.K8sSecret)
+	formats, err := cli.resolveFormats()
+	if err != nil {
+		return err
+	}
+	if len(formats) > 1 {
+		// the templates are written to the default path of each format
+		return nil
+	}
+	outputPath, err := runner.PromptOutputPath(cli.defaultOutputPath(formats[0]))
 	if err != nil {
 		return fmt.Errorf("failed to prompt output path: %w. Please provide a valid path and try again.", err)
 	}
-	cli.Output = outputPath
+	cli.Output = []string{outputPath}
+	if _, err := os.Stat(outputPath); err == nil {
+		// the prompt has confirmed to overwrite the file
+		cli.Force = true
+	}
 	return nil
 }
@@ -2,6 +2,7 @@ package optruck
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/yammerjp/optruck/internal/interactive"
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+	"github.com/yammerjp/optruck/pkg/op"
 
 	"github.com/manifoldco/promptui"
 	"k8s.io/utils/exec"
@@ -85,6 +87,10 @@ func (m *MockExec) Command(cmd string, args ...string) exec.Cmd {
 	}
 }
 
+func (m *MockExec) CommandContext(ctx context.Context, cmd string, args ...string) exec.Cmd {
+	return m.Command(cmd, args...)
+}
+
 func (c *MockCmd) SetStdout(stdout io.Writer) {
 	c.stdout = stdout
 }
@@ -255,8 +261,7 @@ func TestSetDataSourceInteractively(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			utilExec.SetExec(tt.mockExec)
-			err := tt.cli.setDataSourceInteractively(*interactive.NewRunner(tt.mock))
+			err := tt.cli.setDataSourceInteractively(context.Background(), *interactive.NewRunner(tt.mock, utilExec.NewExecutor(tt.mockExec)))
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error but got nil")
@@ -278,12 +283,13 @@ func TestSetDataSourceInteractively(t *testing.T) {
 
 func TestSetTargetAccountInteractively(t *testing.T) {
 	tests := []struct {
-		name      string
-		cli       *CLI
-		mock      *MockRunnable
-		mockExec  *MockExec
-		wantErr   bool
-		wantValue string
+		name                string
+		cli                 *CLI
+		serviceAccountToken string
+		mock                *MockRunnable
+		mockExec            *MockExec
+		wantErr             bool
+		wantValue           string
 	}{
 		{
 			name: "select account",
@@ -344,12 +350,37 @@ func TestSetTargetAccountInteractively(t *testing.T) {
 			wantErr:   true,
 			wantValue: "",
 		},
+		{
+			name:                "service account skips the account",
+			cli:                 &CLI{Vault: "vault1", Item: "item1"},
+			serviceAccountToken: "ops_test",
+			mock:                &MockRunnable{},
+			mockExec:            NewMockExec(),
+			wantErr:             false,
+			wantValue:           "",
+		},
+		{
+			name:                "service account requires the vault",
+			cli:                 &CLI{Item: "item1"},
+			serviceAccountToken: "ops_test",
+			mock:                &MockRunnable{},
+			mockExec:            NewMockExec(),
+			wantErr:             true,
+		},
+		{
+			name:                "service account rejects the account",
+			cli:                 &CLI{Account: "my.1password.com", Vault: "vault1", Item: "item1"},
+			serviceAccountToken: "ops_test",
+			mock:                &MockRunnable{},
+			mockExec:            NewMockExec(),
+			wantErr:             true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			utilExec.SetExec(tt.mockExec)
-			err := tt.cli.setTargetInteractively(*interactive.NewRunner(tt.mock))
+			t.Setenv(op.ServiceAccountTokenEnv, tt.serviceAccountToken)
+			err := tt.cli.setTargetInteractively(context.Background(), *interactive.NewRunner(tt.mock, utilExec.NewExecutor(tt.mockExec)))
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error but got nil")
@@ -444,8 +475,7 @@ func TestSetTargetVaultInteractively(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			utilExec.SetExec(tt.mockExec)
-			err := tt.cli.setTargetInteractively(*interactive.NewRunner(tt.mock))
+			err := tt.cli.setTargetInteractively(context.Background(), *interactive.NewRunner(tt.mock, utilExec.NewExecutor(tt.mockExec)))
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error but got nil")
@@ -549,8 +579,7 @@ func TestSetTargetItemInteractively(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			utilExec.SetExec(tt.mockExec)
-			err := tt.cli.setTargetInteractively(*interactive.NewRunner(tt.mock))
+			err := tt.cli.setTargetInteractively(context.Background(), *interactive.NewRunner(tt.mock, utilExec.NewExecutor(tt.mockExec)))
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error but got nil")
@@ -612,7 +641,7 @@ func TestSetDestInteractively(t *testing.T) {
 		},
 		{
 			name:      "output already set",
-			cli:       &CLI{Output: "existing.env"},
+			cli:       &CLI{OutputOptions: OutputOptions{Output: []string{"existing.env"}}},
 			mock:      &MockRunnable{},
 			wantErr:   false,
 			wantValue: "existing.env",
@@ -649,7 +678,7 @@ func TestSetDestInteractively(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.cli.setDestInteractively(*interactive.NewRunner(tt.mock))
+			err := tt.cli.setDestInteractively(*interactive.NewRunner(tt.mock, nil))
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error but got nil")
@@ -659,8 +688,8 @@ func TestSetDestInteractively(t *testing.T) {
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
-			if tt.cli.Output != tt.wantValue {
-				t.Errorf("Output = %v, want %v", tt.cli.Output, tt.wantValue)
+			if len(tt.cli.Output) != 1 || tt.cli.Output[0] != tt.wantValue {
+				t.Errorf("Output = %v, want [%v]", tt.cli.Output, tt.wantValue)
 			}
 		})
 	}
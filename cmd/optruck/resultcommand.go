@@ -1,6 +1,12 @@
 package optruck
 
-import "github.com/yammerjp/optruck/internal/interactive"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yammerjp/optruck/internal/interactive"
+	"github.com/yammerjp/optruck/pkg/op"
+)
 
 func (cli CLI) buildResultCommand() ([]string, error) {
 	cmds := []string{"optruck", cli.Item}
@@ -14,6 +20,15 @@ func (cli CLI) buildResultCommand() ([]string, error) {
 	if cli.Vault != "" {
 		cmds = append(cmds, "--vault", cli.Vault)
 	}
+	if len(cli.Vaults) > 0 {
+		cmds = append(cmds, "--vaults", strings.Join(cli.Vaults, ","))
+		if cli.Parallel > 1 {
+			cmds = append(cmds, "--parallel", strconv.Itoa(cli.Parallel))
+		}
+		if cli.FailFast {
+			cmds = append(cmds, "--fail-fast")
+		}
+	}
 
 	// data source options
 	if cli.EnvFile != "" {
@@ -23,11 +38,63 @@ func (cli CLI) buildResultCommand() ([]string, error) {
 		if cli.K8sNamespace != interactive.DefaultKubernetesNamespace {
 			cmds = append(cmds, "--k8s-namespace", cli.K8sNamespace)
 		}
+	} else if cli.ComposeFile != "" {
+		cmds = append(cmds, "--compose-file", cli.ComposeFile)
+	} else if cli.ShellFile != "" {
+		cmds = append(cmds, "--shell-file", cli.ShellFile)
+	} else if cli.SystemdEnvFile != "" {
+		cmds = append(cmds, "--systemd-env-file", cli.SystemdEnvFile)
+	}
+	if cli.ComposeService != "" {
+		cmds = append(cmds, "--compose-service", cli.ComposeService)
 	}
 
 	// output options
-	if cli.Output != "" {
-		cmds = append(cmds, "--output", cli.Output)
+	// --format and --output are paired by position
+	for _, path := range cli.Output {
+		cmds = append(cmds, "--output", path)
+	}
+	for _, format := range cli.Format {
+		cmds = append(cmds, "--format", format)
+	}
+	if cli.RefStyle != "" && cli.RefStyle != string(op.RefStyleID) {
+		cmds = append(cmds, "--ref-style", cli.RefStyle)
+	}
+	if cli.VaultVar != "" {
+		cmds = append(cmds, "--vault-var", cli.VaultVar)
+	}
+	if cli.Template != "" {
+		cmds = append(cmds, "--template", cli.Template)
+	}
+	if cli.ComposeEnvFile != "" {
+		cmds = append(cmds, "--compose-env-file", cli.ComposeEnvFile)
+	}
+	if cli.HelmKeyPath != "" {
+		cmds = append(cmds, "--helm-key-path", cli.HelmKeyPath)
+	}
+	if cli.KustomizeEnvFile != "" {
+		cmds = append(cmds, "--kustomize-env-file", cli.KustomizeEnvFile)
+	}
+	if cli.AnsibleLookup != "" {
+		cmds = append(cmds, "--ansible-lookup", cli.AnsibleLookup)
+	}
+	if cli.AnsibleVarPrefix != "" {
+		cmds = append(cmds, "--ansible-var-prefix", cli.AnsibleVarPrefix)
+	}
+	if cli.AnsibleVarCase != "" {
+		cmds = append(cmds, "--ansible-var-case", cli.AnsibleVarCase)
+	}
+	if cli.ESOStore != "" {
+		cmds = append(cmds, "--eso-store", cli.ESOStore)
+	}
+	if cli.ESOStoreKind != "" {
+		cmds = append(cmds, "--eso-store-kind", cli.ESOStoreKind)
+	}
+	if cli.ESORefreshInterval != "" {
+		cmds = append(cmds, "--eso-refresh-interval", cli.ESORefreshInterval)
+	}
+	if cli.Force {
+		cmds = append(cmds, "--force")
 	}
 	return cmds, nil
 }
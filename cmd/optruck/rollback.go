@@ -0,0 +1,45 @@
+package optruck
+
+import (
+	"context"
+	"os"
+
+	"github.com/yammerjp/optruck/internal/interactive"
+	"github.com/yammerjp/optruck/pkg/actions"
+)
+
+func (cmd *RollbackCmd) Run(ctx context.Context) error {
+	// reuse the mirror options to build the same target and template
+	target := CLI{
+		Item:           cmd.Item,
+		Account:        cmd.Account,
+		Vault:          cmd.Vault,
+		K8sSecret:      cmd.K8sSecret,
+		K8sNamespace:   cmd.K8sNamespace,
+		ComposeService: cmd.ComposeService,
+		OutputOptions:  cmd.OutputOptions,
+	}
+	if target.K8sSecret != "" && target.K8sNamespace == "" {
+		target.K8sNamespace = interactive.DefaultKubernetesNamespace
+	}
+	dests, err := target.buildDests()
+	if err != nil {
+		return err
+	}
+	opItemClient, err := target.buildOpItemClient(ctx, true)
+	if err != nil {
+		return err
+	}
+	action := &actions.RollbackConfig{
+		OpItemClient: *opItemClient,
+		Version:      cmd.ToVersion,
+		Dests:        dests,
+		Force:        target.Force,
+		Confirmation: func() error {
+			// confirmed by default
+			return nil
+		},
+		Out: os.Stderr,
+	}
+	return action.Run(ctx)
+}
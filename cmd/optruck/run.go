@@ -1,8 +1,15 @@
 package optruck
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/yammerjp/optruck/internal/interactive"
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 	utilLogger "github.com/yammerjp/optruck/internal/util/logger"
+	"github.com/yammerjp/optruck/pkg/op"
 
 	"github.com/alecthomas/kong"
 )
@@ -19,26 +26,43 @@ var buildInfo BuildInfo
 func Run(bi BuildInfo) {
 	buildInfo = bi
 
-	cli := CLI{}
-	ctx := kong.Parse(&cli,
+	root := Root{}
+	kctx := kong.Parse(&root,
 		kong.Name("optruck"),
 		kong.Description("A CLI tool for managing secrets and creating templates with 1Password."),
 		kong.UsageOnError(),
 		kong.Help(helpPrinter),
 	)
-	if err := cli.Run(); err != nil {
-		ctx.Fatalf("%v", err)
+	utilLogger.SetDefaultLogger(root.LogLevel)
+
+	// Ctrl-C or SIGTERM cancels ctx, which kills the running op or kubectl
+	// command, so optruck can revert what it has done and exit.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx = utilExec.WithCallTimeout(ctx, root.Timeout)
+	ctx = op.WithSessionCache(ctx)
+	kctx.BindTo(ctx, (*context.Context)(nil))
+	err := kctx.Run()
+	stop()
+	if err != nil {
+		code, hint := describeError(err)
+		if hint != "" {
+			kctx.Errorf("%v\n%s", err, hint)
+		} else {
+			kctx.Errorf("%v", err)
+		}
+		kctx.Exit(code)
 	}
 }
 
-func (cli *CLI) Run() error {
-	utilLogger.SetDefaultLogger(cli.LogLevel)
+func (cli *CLI) Run(ctx context.Context) error {
 
 	var confirmation func() error
+	// the uploads are reverted without asking unless interactive
+	var revertConfirmation func(message string) (bool, error)
 
 	if cli.Interactive {
-		runner := *interactive.NewImplRunner()
-		if err := cli.SetOptionsInteractively(runner); err != nil {
+		runner := *interactive.NewImplRunner(cli.executor)
+		if err := cli.SetOptionsInteractively(ctx, runner); err != nil {
 			return err
 		}
 		cmds, err := cli.buildResultCommand()
@@ -48,6 +72,7 @@ func (cli *CLI) Run() error {
 		confirmation = func() error {
 			return runner.Confirm(cmds)
 		}
+		revertConfirmation = runner.ConfirmRevert
 	} else {
 		confirmation = func() error {
 			// confirmed by default
@@ -55,10 +80,10 @@ func (cli *CLI) Run() error {
 		}
 	}
 
-	action, err := cli.buildAction(confirmation)
+	action, err := cli.buildAction(ctx, confirmation, revertConfirmation)
 	if err != nil {
 		return err
 	}
 
-	return action.Run()
+	return action.Run(ctx)
 }
@@ -33,3 +33,17 @@ func (r Runner) Confirm(cmds []string) error {
 	}
 	return nil
 }
+
+// ConfirmRevert asks whether to undo a change to 1Password after a later step
+// failed.
+func (r Runner) ConfirmRevert(message string) (bool, error) {
+	i, _, err := r.Select(promptui.Select{
+		Label:     message,
+		Items:     []string{"yes", "no"},
+		Templates: SelectTemplateBuilder("Revert", "", ""),
+	})
+	if err != nil {
+		return false, err
+	}
+	return i == 0, nil
+}
@@ -8,12 +8,12 @@ import (
 	"github.com/manifoldco/promptui"
 )
 
-func (r Runner) PromptOutputPath(k8sSecret string) (string, error) {
+func (r Runner) PromptOutputPath(defaultPath string) (string, error) {
 	result, err := r.Input(promptui.Prompt{
 		Label:     "Enter output path: ",
 		Validate:  validateOutputPath,
 		Templates: PromptTemplateBuilder("Output Path", ""),
-		Default:   DefaultOutputPath(k8sSecret),
+		Default:   defaultPath,
 	})
 	if err != nil {
 		return "", err
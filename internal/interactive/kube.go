@@ -1,6 +1,7 @@
 package interactive
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/manifoldco/promptui"
@@ -9,9 +10,9 @@ import (
 
 const DefaultKubernetesNamespace = "default"
 
-func (r Runner) SelectKubeNamespace() (string, error) {
-	kubeClient := kube.NewClient()
-	namespaces, err := kubeClient.GetNamespaces()
+func (r Runner) SelectKubeNamespace(ctx context.Context) (string, error) {
+	kubeClient := kube.NewClient(kube.WithExecutor(r.executor))
+	namespaces, err := kubeClient.GetNamespaces(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -27,9 +28,9 @@ func (r Runner) SelectKubeNamespace() (string, error) {
 	return namespaces[i], nil
 }
 
-func (r Runner) SelectKubeSecret(namespace string) (string, error) {
-	kubeClient := kube.NewClient()
-	secrets, err := kubeClient.GetSecrets(namespace)
+func (r Runner) SelectKubeSecret(ctx context.Context, namespace string) (string, error) {
+	kubeClient := kube.NewClient(kube.WithExecutor(r.executor))
+	secrets, err := kubeClient.GetSecrets(ctx, namespace)
 	if err != nil {
 		return "", err
 	}
@@ -1,6 +1,7 @@
 package interactive
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,8 +11,8 @@ import (
 	"github.com/yammerjp/optruck/pkg/op"
 )
 
-func (r Runner) SelectOpAccount() (string, error) {
-	accounts, err := op.NewExecutableClient().ListAccounts()
+func (r Runner) SelectOpAccount(ctx context.Context) (string, error) {
+	accounts, err := op.NewExecutableClient(op.WithExecutor(r.executor)).ListAccounts(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -29,8 +30,8 @@ func (r Runner) SelectOpAccount() (string, error) {
 	return accounts[i].URL, nil
 }
 
-func (r Runner) SelectOpVault(account string) (string, error) {
-	vaults, err := op.NewAccountClient(account).ListVaults()
+func (r Runner) SelectOpVault(ctx context.Context, account string) (string, error) {
+	vaults, err := op.NewAccountClient(account, op.WithExecutor(r.executor)).ListVaults(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -60,8 +61,8 @@ func (r Runner) SelectOpItemOverwriteOrNot() (bool, error) {
 	return result == "overwrite existing", nil
 }
 
-func (r Runner) SelectOpItemName(account, vault string) (string, error) {
-	items, err := op.NewVaultClient(account, vault).ListItems()
+func (r Runner) SelectOpItemName(ctx context.Context, account, vault string) (string, error) {
+	items, err := op.NewVaultClient(account, vault, op.WithExecutor(r.executor)).ListItems(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -80,8 +81,8 @@ func (r Runner) SelectOpItemName(account, vault string) (string, error) {
 	return items[i].ItemID, nil
 }
 
-func (r Runner) PromptOpItemName(account, vault, k8sSecret string) (string, error) {
-	items, err := op.NewVaultClient(account, vault).ListItems()
+func (r Runner) PromptOpItemName(ctx context.Context, account, vault, k8sSecret string) (string, error) {
+	items, err := op.NewVaultClient(account, vault, op.WithExecutor(r.executor)).ListItems(ctx)
 	if err != nil {
 		return "", err
 	}
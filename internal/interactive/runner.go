@@ -1,6 +1,8 @@
 package interactive
 
 import (
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+
 	"github.com/manifoldco/promptui"
 )
 
@@ -19,14 +21,17 @@ func (r *RunnableImpl) Input(prompt promptui.Prompt) (string, error) {
 	return prompt.Run()
 }
 
+// Runner asks for the options, listing the choices with op and kubectl
+// through executor.
 type Runner struct {
 	Runnable
+	executor *utilExec.Executor
 }
 
-func NewRunner(r Runnable) *Runner {
-	return &Runner{Runnable: r}
+func NewRunner(r Runnable, executor *utilExec.Executor) *Runner {
+	return &Runner{Runnable: r, executor: executor}
 }
 
-func NewImplRunner() *Runner {
-	return &Runner{Runnable: &RunnableImpl{}}
+func NewImplRunner(executor *utilExec.Executor) *Runner {
+	return &Runner{Runnable: &RunnableImpl{}, executor: executor}
 }
@@ -0,0 +1,33 @@
+package exec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandError is a command that exited with a failure. It keeps the stderr
+// of the command, and Kind is the reason the Classify function of the
+// command found in it, if any, so that errors.Is can tell the failures
+// apart.
+type CommandError struct {
+	Bin    string
+	Args   []string
+	Stderr string
+	Kind   error
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("`%s %s` failed: %v", e.Bin, strings.Join(e.Args, " "), e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
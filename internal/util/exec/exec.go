@@ -2,40 +2,79 @@ package exec
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log/slog"
+	"os"
+	"strings"
+	"time"
 
+	utilLogger "github.com/yammerjp/optruck/internal/util/logger"
 	execPackage "k8s.io/utils/exec"
 )
 
 type ExecInterface execPackage.Interface
 type ExecCommand execPackage.Cmd
 
-var exec execPackage.Interface
+// host runs the commands of the executors without an exec.Interface of
+// their own.
+var host = execPackage.New()
 
-func init() {
-	exec = execPackage.New()
+// Executor runs the commands of a client, so that each client can have its
+// own runner, e.g. a fake in tests, and its own environment. A nil Executor
+// runs the commands on the host with the environment of optruck.
+type Executor struct {
+	exec execPackage.Interface
+	env  []string
 }
 
-func getExec() execPackage.Interface {
-	return exec
+// NewExecutor returns an executor running the commands through e, or on the
+// host if e is nil. env is added to the environment of optruck for each
+// command, e.g. "OP_SESSION_my=..." or "KUBECONFIG=...".
+func NewExecutor(e execPackage.Interface, env ...string) *Executor {
+	return &Executor{exec: e, env: env}
 }
 
-func SetExec(e execPackage.Interface) {
-	exec = e
+type callTimeoutKey struct{}
+
+// WithCallTimeout returns a context that stops each command run with it after
+// timeout, instead of the whole run. Zero means no timeout.
+func WithCallTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, callTimeoutKey{}, timeout)
 }
 
 type Command struct {
 	ExecCommand
-	bin  string
-	args []string
+	// Classify returns the reason of a failure from the stderr of the
+	// command, or nil if it is not known.
+	Classify func(stderr string) error
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+	bin     string
+	args    []string
 }
 
-func NewCommand(bin string, args ...string) Command {
-	cmd := getExec().Command(bin, args...)
-	return Command{ExecCommand: cmd, bin: bin, args: args}
+// Command builds a command that is killed once ctx is done, e.g. when the
+// call timeout passes or optruck is interrupted.
+func (e *Executor) Command(ctx context.Context, bin string, args ...string) Command {
+	timeout, _ := ctx.Value(callTimeoutKey{}).(time.Duration)
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	runner := host
+	if e != nil && e.exec != nil {
+		runner = e.exec
+	}
+	cmd := runner.CommandContext(ctx, bin, args...)
+	if e != nil && len(e.env) > 0 {
+		// the values are not logged, as they can be session tokens
+		cmd.SetEnv(append(os.Environ(), e.env...))
+	}
+	return Command{ExecCommand: cmd, ctx: ctx, cancel: cancel, timeout: timeout, bin: bin, args: args}
 }
 
 func sealJsonValues(stdin string) string {
@@ -74,9 +113,11 @@ func sealValues(data interface{}) interface{} {
 }
 
 func (c Command) Run(stdin *bytes.Buffer, stdout *bytes.Buffer) error {
+	defer c.cancel()
+	log := utilLogger.FromContext(c.ctx)
 	if stdin != nil {
 		// credentials are have to include json values for sealing
-		slog.Debug("set stdin", "stdin", sealJsonValues(stdin.String()))
+		log.Debug("set stdin", "stdin", sealJsonValues(stdin.String()))
 		c.SetStdin(stdin)
 	}
 	if stdout != nil {
@@ -84,13 +125,29 @@ func (c Command) Run(stdin *bytes.Buffer, stdout *bytes.Buffer) error {
 	}
 	stderr := bytes.NewBuffer(nil)
 	c.SetStderr(stderr)
-	slog.Info("run command", "bin", c.bin, "args", c.args)
+	log.Info("run command", "bin", c.bin, "args", c.args)
 	err := c.ExecCommand.Run()
 	stddErrStr := stderr.String()
 	if stddErrStr != "" {
-		slog.Info("command exec has stderr output", "error", stddErrStr)
+		log.Info("command exec has stderr output", "error", stddErrStr)
 	}
 
+	// the error of a killed command only tells the signal
+	if ctxErr := c.ctx.Err(); err != nil && ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) && c.timeout > 0 {
+			return fmt.Errorf("`%s %s` did not finish in %s, use --timeout to wait longer: %w", c.bin, strings.Join(c.args, " "), c.timeout, ctxErr)
+		}
+		return fmt.Errorf("`%s %s` was stopped: %w", c.bin, strings.Join(c.args, " "), ctxErr)
+	}
+	// only a command that ran has a stderr worth telling
+	var exitErr execPackage.ExitError
+	if errors.As(err, &exitErr) {
+		cmdErr := &CommandError{Bin: c.bin, Args: c.args, Stderr: stddErrStr, Err: err}
+		if c.Classify != nil {
+			cmdErr.Kind = c.Classify(stddErrStr)
+		}
+		return cmdErr
+	}
 	return err
 }
 
@@ -109,6 +166,11 @@ func (c Command) RunWithJSON(stdin interface{}, stdout interface{}) error {
 		if errors.Is(err, execPackage.ErrExecutableNotFound) {
 			return fmt.Errorf("command not found, please install the command `%s`: %w. Ensure the command is installed and try again.", c.bin, err)
 		}
+		// it already tells the command, and its stderr or why it was stopped
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
 		return fmt.Errorf("failed to run command `%s`: %w. Please check the command and try again.", c.bin, err)
 	}
 	err = json.Unmarshal(stdoutBuf.Bytes(), stdout)
@@ -0,0 +1,131 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	execPackage "k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+var errDenied = errors.New("denied")
+
+func newFakeExecutor(action testingexec.FakeAction) *Executor {
+	fakeExec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(cmd string, args ...string) execPackage.Cmd {
+				return testingexec.InitFakeCmd(&testingexec.FakeCmd{RunScript: []testingexec.FakeAction{action}}, cmd, args...)
+			},
+		},
+	}
+	return NewExecutor(fakeExec)
+}
+
+func TestCommandRunWithJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		cancel  bool
+		action  testingexec.FakeAction
+		want    string
+		wantIs  []error
+	}{
+		{
+			name:    "timeout",
+			timeout: time.Millisecond,
+			action: func() ([]byte, []byte, error) {
+				time.Sleep(50 * time.Millisecond)
+				return nil, nil, errors.New("signal: killed")
+			},
+			want:   "`op item get my-app` did not finish in 1ms, use --timeout to wait longer",
+			wantIs: []error{context.DeadlineExceeded},
+		},
+		{
+			name:    "canceled parent",
+			timeout: time.Minute,
+			cancel:  true,
+			action: func() ([]byte, []byte, error) {
+				return nil, nil, errors.New("signal: killed")
+			},
+			want:   "`op item get my-app` was stopped",
+			wantIs: []error{context.Canceled},
+		},
+		{
+			name:    "not found",
+			timeout: time.Minute,
+			action: func() ([]byte, []byte, error) {
+				return nil, nil, execPackage.ErrExecutableNotFound
+			},
+			want:   "command not found, please install the command `op`",
+			wantIs: []error{execPackage.ErrExecutableNotFound},
+		},
+		{
+			name:    "exit failure",
+			timeout: time.Minute,
+			action: func() ([]byte, []byte, error) {
+				return nil, []byte("[ERROR] denied\n"), &testingexec.FakeExitError{Status: 1}
+			},
+			want:   "`op item get my-app` failed: exit 1: [ERROR] denied",
+			wantIs: []error{errDenied},
+		},
+		{
+			name:    "start failure",
+			timeout: time.Minute,
+			action: func() ([]byte, []byte, error) {
+				return nil, nil, errors.New("permission denied")
+			},
+			want: "failed to run command `op`: permission denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(WithCallTimeout(context.Background(), tt.timeout))
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			cmd := newFakeExecutor(tt.action).Command(ctx, "op", "item", "get", "my-app")
+			cmd.Classify = func(stderr string) error {
+				if strings.Contains(stderr, "denied") {
+					return errDenied
+				}
+				return nil
+			}
+			var out map[string]string
+			err := cmd.RunWithJSON(nil, &out)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("RunWithJSON() error = %v, want it to contain %q", err, tt.want)
+			}
+			for _, want := range tt.wantIs {
+				if !errors.Is(err, want) {
+					t.Errorf("RunWithJSON() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandRunWithJSONCommandError(t *testing.T) {
+	cmd := newFakeExecutor(func() ([]byte, []byte, error) {
+		return nil, []byte("[ERROR] unexpected response"), &testingexec.FakeExitError{Status: 2}
+	}).Command(context.Background(), "op", "vault", "list")
+
+	var out []string
+	err := cmd.RunWithJSON(nil, &out)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("RunWithJSON() error = %v, want a CommandError", err)
+	}
+	if cmdErr.Bin != "op" || strings.Join(cmdErr.Args, " ") != "vault list" || cmdErr.Stderr != "[ERROR] unexpected response" || cmdErr.Kind != nil {
+		t.Errorf("CommandError = %+v, want the command and its stderr without a kind", cmdErr)
+	}
+	var exitErr execPackage.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitStatus() != 2 {
+		t.Errorf("RunWithJSON() error = %v, want exit status 2", err)
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"io"
 	"log/slog"
 	"os"
@@ -29,3 +30,19 @@ func SetDefaultLogger(logLevelStr string) {
 
 	slog.SetDefault(slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: logLevel})))
 }
+
+type loggerKey struct{}
+
+// WithAttrs returns a context whose logger adds args to every record, so
+// that the logs of jobs running at the same time can be told apart.
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	return context.WithValue(ctx, loggerKey{}, FromContext(ctx).With(args...))
+}
+
+// FromContext returns the logger set by WithAttrs, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
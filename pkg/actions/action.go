@@ -1,7 +1,12 @@
 package actions
 
+import "context"
+
 type Action interface {
-	Run() error
+	Run(ctx context.Context) error
 }
 
 var _ Action = (*MirrorConfig)(nil)
+var _ Action = (*HistoryConfig)(nil)
+var _ Action = (*RollbackConfig)(nil)
+var _ Action = (*MultiVaultMirrorConfig)(nil)
@@ -0,0 +1,141 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+)
+
+// writeDests renders every dest in memory before writing any file, so that a
+// template failing to render, or replacing a changed file without force,
+// leaves all the files as they were. The result of each dest is reported to
+// out, with the diff of the files that change.
+func writeDests(dests []output.Dest, secretsResp *op.SecretReference, out io.Writer, force bool) error {
+	if out == nil {
+		out = io.Discard
+	}
+
+	files, err := renderDests(dests, secretsResp, out)
+	if err != nil {
+		return err
+	}
+	if err := output.WriteFiles(files, output.WriteOptions{Force: force, Diff: out}); err != nil {
+		slog.Error("failed to write output templates", "error", err)
+		return err
+	}
+	for _, dest := range dests {
+		slog.Debug("Template written successfully", "path", dest.GetPath())
+		fmt.Fprintf(out, "Wrote the template to %s\n", destName(dest))
+	}
+	return nil
+}
+
+// checkDests renders every dest like writeDests, and fails the same way
+// without writing any file. The diffs are only reported if it fails, as
+// writeDests reports them again.
+func checkDests(dests []output.Dest, secretsResp *op.SecretReference, out io.Writer, force bool) error {
+	if out == nil {
+		out = io.Discard
+	}
+
+	files, err := renderDests(dests, secretsResp, out)
+	if err != nil {
+		return err
+	}
+	var diff bytes.Buffer
+	if err := output.CheckFiles(files, output.WriteOptions{Force: force, Diff: &diff}); err != nil {
+		io.Copy(out, &diff)
+		return err
+	}
+	return nil
+}
+
+func renderDests(dests []output.Dest, secretsResp *op.SecretReference, out io.Writer) ([]output.File, error) {
+	files := []output.File{}
+	var errs []error
+	for _, dest := range dests {
+		name := destName(dest)
+		rendered, err := dest.Render(secretsResp)
+		if err != nil {
+			slog.Error("failed to render output template", "path", dest.GetPath(), "error", err)
+			fmt.Fprintf(out, "Failed to render the template for %s\n", name)
+			errs = append(errs, fmt.Errorf("failed to render the template for %s: %w", name, err))
+			continue
+		}
+		files = append(files, rendered...)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return files, nil
+}
+
+// checkValues checks the secrets against the dests that only hold some
+// values, before they are uploaded.
+func checkValues(dests []output.Dest, secrets map[string]string) error {
+	for _, dest := range dests {
+		if checker, ok := dest.(output.ValueChecker); ok {
+			if err := checker.CheckValues(secrets); err != nil {
+				return fmt.Errorf("cannot write the template for %s: %w", destName(dest), err)
+			}
+		}
+	}
+	return nil
+}
+
+func destName(dest output.Dest) string {
+	if dest.GetPath() == output.StdoutPath {
+		return "stdout"
+	}
+	return dest.GetPath()
+}
+
+// pendingReference returns the reference of the item as pending will leave
+// it, once the names the dests reference it by are checked. An overwritten
+// item keeps its IDs, so its reference is the final one; that of a new item
+// holds placeholders for the IDs 1Password has yet to assign, which is
+// enough to tell whether a file would change.
+func pendingReference(ctx context.Context, client op.ItemClient, dests []output.Dest, pending *op.PendingUpload) (*op.SecretReference, error) {
+	ref := *pending.SecretReference
+	if pending.Creates() {
+		if needsNameReference(dests) {
+			vault, err := client.GetVault(ctx)
+			if err != nil {
+				return nil, err
+			}
+			ref.VaultName, ref.VaultID = vault.Name, vault.ID
+		}
+		if ref.VaultID == "" {
+			ref.VaultID = "<vault id>"
+		}
+		ref.ItemID = "<new item id>"
+	}
+	if err := validateRefStyle(ctx, client, dests, &ref); err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
+
+// validateRefStyle checks the names of the vault and the item once if any of
+// the dests references them by name.
+func validateRefStyle(ctx context.Context, client op.ItemClient, dests []output.Dest, secretsResp *op.SecretReference) error {
+	if needsNameReference(dests) {
+		return client.ValidateNameReference(ctx, secretsResp)
+	}
+	return nil
+}
+
+func needsNameReference(dests []output.Dest) bool {
+	for _, dest := range dests {
+		if dest.GetRefOptions().NeedsNameReference() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+)
+
+type fakeDest struct {
+	path       string
+	refOptions op.RefOptions
+	err        error
+	rendered   *op.SecretReference
+}
+
+// Render renders no files, so that nothing is written outside of the test.
+func (d *fakeDest) Render(resp *op.SecretReference) ([]output.File, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	d.rendered = resp
+	return nil, nil
+}
+
+func (d *fakeDest) GetPath() string {
+	return d.path
+}
+
+func (d *fakeDest) GetBasename() string {
+	return filepath.Base(d.path)
+}
+
+func (d *fakeDest) GetRefOptions() op.RefOptions {
+	return d.refOptions
+}
+
+func TestWriteDests(t *testing.T) {
+	ref := &op.SecretReference{ItemName: "my-app"}
+	errRender := errors.New("no such field")
+
+	tests := []struct {
+		name       string
+		dests      []*fakeDest
+		wantOut    string
+		wantErrors []string
+	}{
+		{
+			name:    "all written",
+			dests:   []*fakeDest{{path: ".env.1password"}, {path: output.StdoutPath}},
+			wantOut: "Wrote the template to .env.1password\nWrote the template to stdout\n",
+		},
+		{
+			name:       "render failure writes none of the templates",
+			dests:      []*fakeDest{{path: "a.yaml", err: errRender}, {path: "b.env"}, {path: "c.tf", err: errRender}},
+			wantOut:    "Failed to render the template for a.yaml\nFailed to render the template for c.tf\n",
+			wantErrors: []string{"a.yaml: no such field", "c.tf: no such field"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dests := make([]output.Dest, 0, len(tt.dests))
+			for _, d := range tt.dests {
+				dests = append(dests, d)
+			}
+			var out bytes.Buffer
+
+			err := writeDests(dests, ref, &out, false)
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("writeDests() out = %q, want %q", got, tt.wantOut)
+			}
+			if len(tt.wantErrors) == 0 && err != nil {
+				t.Fatalf("writeDests() error = %v", err)
+			}
+			for _, want := range tt.wantErrors {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("writeDests() error = %v, want it to contain %q", err, want)
+				}
+			}
+			if len(tt.wantErrors) > 0 && !errors.Is(err, errRender) {
+				t.Errorf("writeDests() error = %v, want it to wrap %v", err, errRender)
+			}
+			for _, d := range tt.dests {
+				if d.err == nil && d.rendered != ref {
+					t.Errorf("dest %s was not rendered", d.path)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckValues(t *testing.T) {
+	secrets := map[string]string{"DB_PASSWORD": "it's"}
+
+	if err := checkValues([]output.Dest{&output.EnvTemplateDest{Path: ".env.1password"}}, secrets); err != nil {
+		t.Errorf("checkValues() error = %v for a dest that takes any value", err)
+	}
+	err := checkValues([]output.Dest{&output.EnvTemplateDest{Path: ".env.1password"}, &output.ShellDest{Path: "env.sh.1password"}}, secrets)
+	if err == nil || !strings.Contains(err.Error(), "env.sh.1password") {
+		t.Errorf("checkValues() error = %v, want one for env.sh.1password", err)
+	}
+}
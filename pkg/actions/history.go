@@ -0,0 +1,73 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+type HistoryConfig struct {
+	OpItemClient op.ItemClient
+	Out          io.Writer
+}
+
+func (config HistoryConfig) Run(ctx context.Context) error {
+	slog.Debug("Starting history action for item", "item", config.OpItemClient.ItemName)
+
+	versions, err := config.OpItemClient.ListVersions(ctx)
+	if err != nil {
+		slog.Error("failed to list item versions", "error", err)
+		return err
+	}
+
+	w := tabwriter.NewWriter(config.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tUPDATED AT\tCHANGED FIELDS")
+	var prev map[string]string
+	for _, v := range versions {
+		version := fmt.Sprintf("%d", v.Version)
+		if v.Current {
+			version += " (current)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", version, v.UpdatedAt, strings.Join(changedFields(prev, v.Fields), " "))
+		prev = v.Fields
+	}
+	return w.Flush()
+}
+
+// changedFields describes the difference between two versions by label only,
+// so secret values never reach the terminal.
+func changedFields(prev, next map[string]string) []string {
+	labels := make([]string, 0, len(next))
+	for label := range next {
+		labels = append(labels, label)
+	}
+	for label := range prev {
+		if _, ok := next[label]; !ok {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+
+	changes := []string{}
+	for _, label := range labels {
+		prevValue, inPrev := prev[label]
+		nextValue, inNext := next[label]
+		switch {
+		case prev == nil:
+			changes = append(changes, label)
+		case !inPrev:
+			changes = append(changes, "+"+label)
+		case !inNext:
+			changes = append(changes, "-"+label)
+		case prevValue != nextValue:
+			changes = append(changes, "~"+label)
+		}
+	}
+	return changes
+}
@@ -1,6 +1,8 @@
 package actions
 
 import (
+	"context"
+	"io"
 	"log/slog"
 
 	"github.com/yammerjp/optruck/pkg/datasources"
@@ -8,15 +10,27 @@ import (
 	"github.com/yammerjp/optruck/pkg/output"
 )
 
+// MirrorConfig uploads the secrets of the data source to an item and writes
+// the same reference to every dest. Out receives a line per dest telling
+// whether it was written; it should not be stdout, where a dest may write.
+// Force replaces the existing templates that differ from the new ones.
+//
+// The templates are rendered and checked before the upload, so that a name
+// that cannot be referenced or a changed file without Force leaves 1Password
+// as it was. If the templates still cannot be written after the upload, the
+// upload is reverted once RevertConfirmation agrees, or always if it is nil.
 type MirrorConfig struct {
-	OpItemClient op.ItemClient
-	DataSource   datasources.Source
-	Dest         output.Dest
-	Overwrite    bool
-	Confirmation func() error
+	OpItemClient       op.ItemClient
+	DataSource         datasources.Source
+	Dests              []output.Dest
+	Force              bool
+	Overwrite          bool
+	Confirmation       func() error
+	RevertConfirmation func(message string) (bool, error)
+	Out                io.Writer
 }
 
-func (config MirrorConfig) Run() error {
+func (config MirrorConfig) Run(ctx context.Context) error {
 	slog.Debug("Starting mirror action for item", "item", config.OpItemClient.ItemName)
 
 	if err := config.Confirmation(); err != nil {
@@ -24,26 +38,43 @@ func (config MirrorConfig) Run() error {
 		return err
 	}
 
-	secrets, err := config.DataSource.FetchSecrets()
+	secrets, err := config.DataSource.FetchSecrets(ctx)
 	if err != nil {
 		slog.Error("failed to fetch secrets from data source", "error", err)
 		return err
 	}
 	slog.Debug("Fetched secrets from data source", "count", len(secrets))
+	if err := checkValues(config.Dests, secrets); err != nil {
+		slog.Error("failed to check the secrets against the templates", "error", err)
+		return err
+	}
 
-	secretsResp, err := config.OpItemClient.UploadItem(secrets, config.Overwrite)
+	pending, err := config.OpItemClient.PrepareUpload(ctx, secrets, config.Overwrite)
 	if err != nil {
 		slog.Error("failed to upload secrets to 1Password", "error", err)
 		return err
 	}
-	slog.Debug("Uploaded secrets to 1Password successfully")
+	ref, err := pendingReference(ctx, config.OpItemClient, config.Dests, pending)
+	if err != nil {
+		slog.Error("failed to validate the secret reference", "error", err)
+		return err
+	}
+	if err := checkDests(config.Dests, ref, config.Out, config.Force); err != nil {
+		slog.Error("failed to check the templates before the upload", "error", err)
+		return err
+	}
 
-	err = config.Dest.Write(secretsResp)
+	upload, err := config.OpItemClient.CommitUpload(ctx, pending)
 	if err != nil {
-		slog.Error("failed to write output template", "error", err)
+		slog.Error("failed to upload secrets to 1Password", "error", err)
+		return err
+	}
+	slog.Debug("Uploaded secrets to 1Password successfully")
+
+	if err := writeDests(config.Dests, upload.SecretReference, config.Out, config.Force); err != nil {
+		revertUploads(ctx, []op.ItemClient{config.OpItemClient}, []*op.Upload{upload}, config.RevertConfirmation, config.Out)
 		return err
 	}
-	slog.Debug("Template written to %s successfully", "path", config.Dest.GetPath())
 
 	slog.Debug("Mirror action completed successfully")
 	return nil
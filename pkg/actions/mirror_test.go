@@ -0,0 +1,94 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+	"github.com/yammerjp/optruck/pkg/datasources"
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestMirrorChecksTemplatesBeforeUpload(t *testing.T) {
+	notFound := func() ([]byte, []byte, error) {
+		return nil, []byte(`[ERROR] "my app" isn't an item in the "dev" vault.`), &testingexec.FakeExitError{Status: 1}
+	}
+	vaultList := func() ([]byte, []byte, error) {
+		return []byte(`[{"id": "dev-id", "name": "dev"}]`), nil, nil
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		refStyle op.RefStyle
+		actions  []testingexec.FakeAction
+		wantCmds [][]string
+		wantErr  error
+	}{
+		{
+			name:     "changed template without force",
+			existing: "FOO=\"{{op://old-vault/old-item/FOO}}\"\n",
+			actions:  []testingexec.FakeAction{notFound},
+			wantCmds: [][]string{{"item", "get"}},
+			wantErr:  output.ErrTemplateChanged,
+		},
+		{
+			name:     "item name that cannot be referenced",
+			refStyle: op.RefStyleName,
+			actions:  []testingexec.FakeAction{notFound, vaultList},
+			wantCmds: [][]string{{"item", "get"}, {"vault", "list"}},
+			wantErr:  op.ErrNameNotReferenceSafe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			envFile := filepath.Join(dir, ".env")
+			if err := os.WriteFile(envFile, []byte("FOO=bar\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			template := filepath.Join(dir, ".env.1password")
+			if tt.existing != "" {
+				if err := os.WriteFile(template, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var gotCmds [][]string
+			fakeExec := &testingexec.FakeExec{}
+			for _, action := range tt.actions {
+				fakeExec.CommandScript = append(fakeExec.CommandScript, func(cmd string, args ...string) exec.Cmd {
+					gotCmds = append(gotCmds, args[:2])
+					return &testingexec.FakeCmd{RunScript: []testingexec.FakeAction{action}}
+				})
+			}
+			config := MirrorConfig{
+				OpItemClient: *op.NewItemClient("my.1password.com", "dev", "my app", op.WithExecutor(utilExec.NewExecutor(fakeExec))),
+				DataSource:   &datasources.EnvFileSource{Path: envFile},
+				Dests:        []output.Dest{&output.EnvTemplateDest{Path: template, RefOptions: op.RefOptions{RefStyle: tt.refStyle}}},
+				Confirmation: func() error { return nil },
+			}
+
+			err := config.Run(context.Background())
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotCmds, tt.wantCmds) {
+				t.Errorf("op commands = %v, want %v, without uploading", gotCmds, tt.wantCmds)
+			}
+			if got, _ := os.ReadFile(template); string(got) != tt.existing {
+				t.Errorf("template = %q, want %q", got, tt.existing)
+			}
+		})
+	}
+}
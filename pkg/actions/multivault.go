@@ -0,0 +1,269 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	utilLogger "github.com/yammerjp/optruck/internal/util/logger"
+	"github.com/yammerjp/optruck/pkg/datasources"
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+)
+
+// MultiVaultMirrorConfig uploads the same secrets to an item of the same name
+// in several vaults, and writes one template that resolves against all of
+// them through the vault variable of each dest. Up to Parallel vaults are
+// uploaded to at the same time, and the result of each is reported to Out in
+// the order of the vaults. Like MirrorConfig, the template is checked against
+// the item in every vault before any upload, and the uploads are reverted if
+// a later step fails.
+type MultiVaultMirrorConfig struct {
+	OpItemClients      []op.ItemClient
+	DataSource         datasources.Source
+	Dests              []output.Dest
+	Force              bool
+	Overwrite          bool
+	Confirmation       func() error
+	RevertConfirmation func(message string) (bool, error)
+	Out                io.Writer
+	// Parallel is the number of vaults to upload to at the same time, one
+	// if it is not set.
+	Parallel int
+	// FailFast stops starting uploads once one fails. With it unset, every
+	// vault is tried so that all the failures are reported at once.
+	FailFast bool
+}
+
+func (config MultiVaultMirrorConfig) Run(ctx context.Context) error {
+	slog.Debug("Starting multi-vault mirror action", "vaults", len(config.OpItemClients))
+
+	for _, dest := range config.Dests {
+		if dest.GetRefOptions().VaultVar == "" {
+			return fmt.Errorf("a vault variable is required to share one template between vaults, please specify it with --vault-var option")
+		}
+	}
+
+	if err := config.Confirmation(); err != nil {
+		slog.Error("failed to confirm", "error", err)
+		return err
+	}
+
+	secrets, err := config.DataSource.FetchSecrets(ctx)
+	if err != nil {
+		slog.Error("failed to fetch secrets from data source", "error", err)
+		return err
+	}
+	slog.Debug("Fetched secrets from data source", "count", len(secrets))
+	if err := checkValues(config.Dests, secrets); err != nil {
+		slog.Error("failed to check the secrets against the templates", "error", err)
+		return err
+	}
+
+	pendings, err := config.prepareUploads(ctx, secrets)
+	if err != nil {
+		slog.Error("failed to check the vaults before the upload", "error", err)
+		return err
+	}
+
+	if uploads, err := config.uploadAndWrite(ctx, pendings); err != nil {
+		revertUploads(ctx, config.OpItemClients, uploads, config.RevertConfirmation, config.Out)
+		return err
+	}
+
+	slog.Debug("Multi-vault mirror action completed successfully")
+	return nil
+}
+
+// prepareUploads looks up the item in every vault, and checks the template
+// against all of them before anything is uploaded.
+func (config MultiVaultMirrorConfig) prepareUploads(ctx context.Context, secrets map[string]string) ([]*op.PendingUpload, error) {
+	type prepared struct {
+		pending *op.PendingUpload
+		ref     *op.SecretReference
+	}
+	results, errs := runPool(ctx, len(config.OpItemClients), config.Parallel, config.FailFast, func(ctx context.Context, i int) (prepared, error) {
+		client := config.OpItemClients[i]
+		ctx = utilLogger.WithAttrs(ctx, "item", client.ItemName, "vault", client.Vault)
+		log := utilLogger.FromContext(ctx)
+
+		pending, err := client.PrepareUpload(ctx, secrets, config.Overwrite)
+		if err != nil {
+			log.Error("failed to upload secrets to 1Password", "error", err)
+			return prepared{}, fmt.Errorf("failed to upload secrets to vault %s: %w", client.Vault, err)
+		}
+		ref, err := pendingReference(ctx, client, config.Dests, pending)
+		if err != nil {
+			log.Error("failed to validate the secret reference", "error", err)
+			return prepared{}, fmt.Errorf("vault %s: %w", client.Vault, err)
+		}
+		return prepared{pending: pending, ref: ref}, nil
+	})
+	if err := config.reportVaults(errs, nil); err != nil {
+		return nil, err
+	}
+
+	pendings := make([]*op.PendingUpload, len(results))
+	refs := make([]*op.SecretReference, len(results))
+	for i, result := range results {
+		pendings[i], refs[i] = result.pending, result.ref
+	}
+	if err := checkSameFields(refs, config.Dests); err != nil {
+		return nil, err
+	}
+	if err := checkDests(config.Dests, refs[0], config.Out, config.Force); err != nil {
+		return nil, err
+	}
+	return pendings, nil
+}
+
+// uploadAndWrite returns the uploads done even if it fails, so that they can
+// be reverted. The uploads are in the order of the clients, nil for the
+// vaults that were not uploaded to.
+func (config MultiVaultMirrorConfig) uploadAndWrite(ctx context.Context, pendings []*op.PendingUpload) ([]*op.Upload, error) {
+	uploads, errs := runPool(ctx, len(config.OpItemClients), config.Parallel, config.FailFast, func(ctx context.Context, i int) (*op.Upload, error) {
+		client := config.OpItemClients[i]
+		ctx = utilLogger.WithAttrs(ctx, "item", client.ItemName, "vault", client.Vault)
+		log := utilLogger.FromContext(ctx)
+
+		upload, err := client.CommitUpload(ctx, pendings[i])
+		if err != nil {
+			log.Error("failed to upload secrets to 1Password", "error", err)
+			return nil, fmt.Errorf("failed to upload secrets to vault %s: %w", client.Vault, err)
+		}
+		log.Debug("Uploaded secrets to 1Password successfully")
+		return upload, nil
+	})
+	refs := make([]*op.SecretReference, 0, len(uploads))
+	err := config.reportVaults(errs, func(i int) {
+		refs = append(refs, uploads[i].SecretReference)
+	})
+	if err != nil {
+		return uploads, err
+	}
+
+	// the IDs of the new items are only known now
+	if err := checkSameFields(refs, config.Dests); err != nil {
+		slog.Error("items differ between vaults", "error", err)
+		return uploads, err
+	}
+
+	return uploads, writeDests(config.Dests, refs[0], config.Out, config.Force)
+}
+
+// reportVaults reports the failed and skipped vaults to Out in the order of
+// the vaults, and returns their errors. The skipped vaults only matter if
+// nothing failed, e.g. on an interrupt between two uploads. uploaded is
+// called for the other vaults, which are reported as uploaded if it is set.
+func (config MultiVaultMirrorConfig) reportVaults(errs []error, uploaded func(i int)) error {
+	out := config.Out
+	if out == nil {
+		out = io.Discard
+	}
+
+	var failures, skips []error
+	for i, client := range config.OpItemClients {
+		switch err := errs[i]; {
+		case errors.Is(err, errSkipped):
+			fmt.Fprintf(out, "Skipped vault %s: %v\n", client.Vault, err)
+			skips = append(skips, fmt.Errorf("vault %s: %w", client.Vault, err))
+		case err != nil:
+			fmt.Fprintf(out, "Failed to upload item %s to vault %s: %v\n", client.ItemName, client.Vault, err)
+			failures = append(failures, err)
+		case uploaded != nil:
+			fmt.Fprintf(out, "Uploaded item %s to vault %s.\n", client.ItemName, client.Vault)
+			uploaded(i)
+		}
+	}
+	if len(failures) == 0 {
+		failures = skips
+	}
+	return errors.Join(failures...)
+}
+
+// checkSameFields makes sure that the shared template, rendered from the
+// first item, resolves in every vault. Every item must have the same fields,
+// and each dest must reference them the same way: a label that is not
+// reference-safe is referenced by its field ID, which may differ between
+// items that existed before. Overwriting an item keeps the fields and IDs it
+// already had, so the items can differ.
+func checkSameFields(refs []*op.SecretReference, dests []output.Dest) error {
+	if len(refs) == 0 {
+		return fmt.Errorf("no vaults to mirror to")
+	}
+	want := fieldSet(refs[0])
+	for _, ref := range refs[1:] {
+		got := fieldSet(ref)
+		missing := []string{}
+		for label := range want {
+			if !got[label] {
+				missing = append(missing, label)
+			}
+		}
+		extra := []string{}
+		for label := range got {
+			if !want[label] {
+				extra = append(extra, label)
+			}
+		}
+		if len(missing) == 0 && len(extra) == 0 {
+			continue
+		}
+		sort.Strings(missing)
+		sort.Strings(extra)
+		return fmt.Errorf("item %s in vault %s has different fields from the one in vault %s (missing: [%s], extra: [%s]). Please align the fields in 1Password and try again.",
+			ref.ItemName, ref.VaultName, refs[0].VaultName, strings.Join(missing, " "), strings.Join(extra, " "))
+	}
+
+	for _, dest := range dests {
+		opts := dest.GetRefOptions()
+		want, err := fieldRefs(refs[0], opts)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs[1:] {
+			got, err := fieldRefs(ref, opts)
+			if err != nil {
+				return err
+			}
+			differ := []string{}
+			for label, uri := range want {
+				if got[label] != uri {
+					differ = append(differ, label)
+				}
+			}
+			if len(differ) == 0 {
+				continue
+			}
+			sort.Strings(differ)
+			return fmt.Errorf("fields [%s] of item %s are referenced by different field IDs in vaults %s and %s, so the template would not resolve in vault %s. Please rename the fields to use only letters, digits, '-' and '_', or recreate them, and try again.",
+				strings.Join(differ, " "), ref.ItemName, refs[0].VaultName, ref.VaultName, ref.VaultName)
+		}
+	}
+	return nil
+}
+
+// fieldRefs returns the reference of each field by its label.
+func fieldRefs(ref *op.SecretReference, opts op.RefOptions) (map[string]string, error) {
+	fieldRefs, err := ref.GetFieldRefsFor(opts)
+	if err != nil {
+		return nil, err
+	}
+	uris := make(map[string]string, len(fieldRefs))
+	for _, fieldRef := range fieldRefs {
+		uris[fieldRef.Label] = fieldRef.URI()
+	}
+	return uris, nil
+}
+
+func fieldSet(ref *op.SecretReference) map[string]bool {
+	set := make(map[string]bool, len(ref.FieldLabels))
+	for _, label := range ref.FieldLabels {
+		set[label] = true
+	}
+	return set
+}
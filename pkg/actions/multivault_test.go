@@ -0,0 +1,218 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+	"github.com/yammerjp/optruck/pkg/datasources"
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestCheckSameFields(t *testing.T) {
+	dests := []output.Dest{&fakeDest{path: ".env.1password", refOptions: op.RefOptions{VaultVar: "APP_ENV"}}}
+
+	tests := []struct {
+		name    string
+		refs    []*op.SecretReference
+		wantErr bool
+	}{
+		{
+			name: "same fields in different order",
+			refs: []*op.SecretReference{
+				{VaultName: "dev", ItemName: "my-app", FieldLabels: []string{"FOO", "BAR"}, FieldIDs: []string{"FOO", "BAR"}},
+				{VaultName: "prod", ItemName: "my-app", FieldLabels: []string{"BAR", "FOO"}, FieldIDs: []string{"BAR", "FOO"}},
+			},
+		},
+		{
+			name: "missing field",
+			refs: []*op.SecretReference{
+				{VaultName: "dev", ItemName: "my-app", FieldLabels: []string{"FOO", "BAR"}},
+				{VaultName: "prod", ItemName: "my-app", FieldLabels: []string{"FOO"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "extra field left by overwrite",
+			refs: []*op.SecretReference{
+				{VaultName: "dev", ItemName: "my-app", FieldLabels: []string{"FOO"}},
+				{VaultName: "staging", ItemName: "my-app", FieldLabels: []string{"FOO"}},
+				{VaultName: "prod", ItemName: "my-app", FieldLabels: []string{"FOO", "OLD"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "same id of a label that is not reference-safe",
+			refs: []*op.SecretReference{
+				{VaultName: "dev", ItemName: "my-app", FieldLabels: []string{"tls.crt"}, FieldIDs: []string{"tls_crt-1a2b3c4d"}},
+				{VaultName: "prod", ItemName: "my-app", FieldLabels: []string{"tls.crt"}, FieldIDs: []string{"tls_crt-1a2b3c4d"}},
+			},
+		},
+		{
+			name: "different ids of a label that is not reference-safe",
+			refs: []*op.SecretReference{
+				{VaultName: "dev", ItemName: "my-app", FieldLabels: []string{"tls.crt"}, FieldIDs: []string{"tls_crt-1a2b3c4d"}},
+				{VaultName: "prod", ItemName: "my-app", FieldLabels: []string{"tls.crt"}, FieldIDs: []string{"tls.crt"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no vaults",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSameFields(tt.refs, dests)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSameFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMultiVaultPrepareUploads(t *testing.T) {
+	// each vault has its own exec, as the lookups run at the same time
+	deniedExec := func() *testingexec.FakeExec {
+		return &testingexec.FakeExec{
+			CommandScript: []testingexec.FakeCommandAction{
+				func(cmd string, args ...string) exec.Cmd {
+					return &testingexec.FakeCmd{
+						RunScript: []testingexec.FakeAction{
+							func() ([]byte, []byte, error) {
+								return nil, []byte("[ERROR] permission denied"), &testingexec.FakeExitError{Status: 1}
+							},
+						},
+					}
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		parallel  int
+		failFast  bool
+		wantOut   string
+		wantCalls []int
+	}{
+		{
+			name:     "reports every vault in order",
+			parallel: 3,
+			wantOut: "Failed to upload item my-app to vault dev: failed to upload secrets to vault dev: failed to look up the item: `op item get my-app --reveal --account my.1password.com --vault dev --format json` failed: exit 1: [ERROR] permission denied. Please check the item name and try again.\n" +
+				"Failed to upload item my-app to vault staging: failed to upload secrets to vault staging: failed to look up the item: `op item get my-app --reveal --account my.1password.com --vault staging --format json` failed: exit 1: [ERROR] permission denied. Please check the item name and try again.\n" +
+				"Failed to upload item my-app to vault prod: failed to upload secrets to vault prod: failed to look up the item: `op item get my-app --reveal --account my.1password.com --vault prod --format json` failed: exit 1: [ERROR] permission denied. Please check the item name and try again.\n",
+			wantCalls: []int{1, 1, 1},
+		},
+		{
+			name:     "fail fast skips the other vaults",
+			parallel: 1,
+			failFast: true,
+			wantOut: "Failed to upload item my-app to vault dev: failed to upload secrets to vault dev: failed to look up the item: `op item get my-app --reveal --account my.1password.com --vault dev --format json` failed: exit 1: [ERROR] permission denied. Please check the item name and try again.\n" +
+				"Skipped vault staging: skipped after another one failed\n" +
+				"Skipped vault prod: skipped after another one failed\n",
+			wantCalls: []int{1, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clients []op.ItemClient
+			var execs []*testingexec.FakeExec
+			for _, vault := range []string{"dev", "staging", "prod"} {
+				e := deniedExec()
+				execs = append(execs, e)
+				clients = append(clients, *op.NewItemClient("my.1password.com", vault, "my-app", op.WithExecutor(utilExec.NewExecutor(e))))
+			}
+			var out bytes.Buffer
+			config := MultiVaultMirrorConfig{OpItemClients: clients, Out: &out, Parallel: tt.parallel, FailFast: tt.failFast}
+
+			_, err := config.prepareUploads(context.Background(), map[string]string{"FOO": "bar"})
+
+			if !errors.Is(err, op.ErrPermissionDenied) {
+				t.Errorf("err = %v, want %v", err, op.ErrPermissionDenied)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("out = %q, want %q", got, tt.wantOut)
+			}
+			for i, e := range execs {
+				if e.CommandCalls != tt.wantCalls[i] {
+					t.Errorf("vault %s: got %d commands, want %d", clients[i].Vault, e.CommandCalls, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMultiVaultChecksTemplatesBeforeUpload(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("FOO=bar\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	template := filepath.Join(dir, ".env.1password")
+	existing := "FOO=\"{{op://${APP_ENV}/old-item/FOO}}\"\n"
+	if err := os.WriteFile(template, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the item is missing from every vault, and is looked up by name once
+	// the vault is known
+	var mu sync.Mutex
+	var gotCmds []string
+	fakeExec := func(vault string) *testingexec.FakeExec {
+		e := &testingexec.FakeExec{}
+		for range 4 {
+			e.CommandScript = append(e.CommandScript, func(cmd string, args ...string) exec.Cmd {
+				mu.Lock()
+				gotCmds = append(gotCmds, args[0]+" "+args[1])
+				mu.Unlock()
+				return &testingexec.FakeCmd{RunScript: []testingexec.FakeAction{
+					func() ([]byte, []byte, error) {
+						if args[0] == "vault" {
+							return []byte(`[{"id": "` + vault + `-id", "name": "` + vault + `"}]`), nil, nil
+						}
+						return nil, []byte(`[ERROR] "my-app" isn't an item in the "` + vault + `" vault.`), &testingexec.FakeExitError{Status: 1}
+					},
+				}}
+			})
+		}
+		return e
+	}
+	var clients []op.ItemClient
+	for _, vault := range []string{"dev", "prod"} {
+		clients = append(clients, *op.NewItemClient("my.1password.com", vault, "my-app", op.WithExecutor(utilExec.NewExecutor(fakeExec(vault)))))
+	}
+	config := MultiVaultMirrorConfig{
+		OpItemClients: clients,
+		DataSource:    &datasources.EnvFileSource{Path: envFile},
+		Dests:         []output.Dest{&output.EnvTemplateDest{Path: template, RefOptions: op.RefOptions{VaultVar: "APP_ENV"}}},
+		Confirmation:  func() error { return nil },
+		Parallel:      2,
+	}
+
+	err := config.Run(context.Background())
+
+	if !errors.Is(err, output.ErrTemplateChanged) {
+		t.Errorf("Run() error = %v, want %v", err, output.ErrTemplateChanged)
+	}
+	for _, cmd := range gotCmds {
+		if cmd == "item create" {
+			t.Errorf("op commands = %v, want no upload", gotCmds)
+			break
+		}
+	}
+	if got, _ := os.ReadFile(template); string(got) != existing {
+		t.Errorf("template = %q, want %q", got, existing)
+	}
+}
@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var errSkipped = errors.New("skipped")
+
+// runPool runs task for each of n jobs, at most parallel of them at the same
+// time, and returns the results and the errors in the order of the jobs
+// whatever order they finish in. Once ctx is done, or once a job fails with
+// failFast set, the jobs not started yet are skipped with an error wrapping
+// errSkipped, but the running ones finish so that what they did can be
+// reverted.
+func runPool[T any](ctx context.Context, n, parallel int, failFast bool, task func(ctx context.Context, i int) (T, error)) ([]T, []error) {
+	results := make([]T, n)
+	errs := make([]error, n)
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var failed atomic.Bool
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(parallel, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					errs[i] = fmt.Errorf("%w: %w", errSkipped, err)
+					continue
+				}
+				if failFast && failed.Load() {
+					errs[i] = fmt.Errorf("%w after another one failed", errSkipped)
+					continue
+				}
+				results[i], errs[i] = task(ctx, i)
+				if errs[i] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results, errs
+}
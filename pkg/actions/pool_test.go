@@ -0,0 +1,133 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunPool(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name        string
+		n           int
+		parallel    int
+		failFast    bool
+		fail        map[int]bool
+		wantResults []int
+		wantErrs    []error
+		wantSkipped []bool
+	}{
+		{
+			name:        "results in the order of the jobs",
+			n:           5,
+			parallel:    3,
+			wantResults: []int{0, 10, 20, 30, 40},
+			wantErrs:    []error{nil, nil, nil, nil, nil},
+			wantSkipped: []bool{false, false, false, false, false},
+		},
+		{
+			name:        "zero parallel runs one at a time",
+			n:           2,
+			parallel:    0,
+			wantResults: []int{0, 10},
+			wantErrs:    []error{nil, nil},
+			wantSkipped: []bool{false, false},
+		},
+		{
+			name:        "runs every job after a failure",
+			n:           3,
+			parallel:    1,
+			fail:        map[int]bool{0: true},
+			wantResults: []int{0, 10, 20},
+			wantErrs:    []error{errFailed, nil, nil},
+			wantSkipped: []bool{false, false, false},
+		},
+		{
+			name:        "fail fast skips the jobs not started",
+			n:           3,
+			parallel:    1,
+			failFast:    true,
+			fail:        map[int]bool{0: true},
+			wantResults: []int{0, 0, 0},
+			wantErrs:    []error{errFailed, errSkipped, errSkipped},
+			wantSkipped: []bool{false, true, true},
+		},
+		{
+			name:        "no jobs",
+			n:           0,
+			parallel:    4,
+			wantResults: []int{},
+			wantErrs:    []error{},
+			wantSkipped: []bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, errs := runPool(context.Background(), tt.n, tt.parallel, tt.failFast, func(ctx context.Context, i int) (int, error) {
+				// the later jobs finish first
+				time.Sleep(time.Duration(tt.n-i) * time.Millisecond)
+				if tt.fail[i] {
+					return 0, errFailed
+				}
+				return i * 10, nil
+			})
+
+			if !reflect.DeepEqual(results, tt.wantResults) {
+				t.Errorf("results = %v, want %v", results, tt.wantResults)
+			}
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("got %d errors, want %d", len(errs), len(tt.wantErrs))
+			}
+			for i, err := range errs {
+				if !errors.Is(err, tt.wantErrs[i]) || (err == nil) != (tt.wantErrs[i] == nil) {
+					t.Errorf("errs[%d] = %v, want %v", i, err, tt.wantErrs[i])
+				}
+				if got := errors.Is(err, errSkipped); got != tt.wantSkipped[i] {
+					t.Errorf("errs[%d] skipped = %v, want %v", i, got, tt.wantSkipped[i])
+				}
+			}
+		})
+	}
+
+	t.Run("at most parallel jobs at the same time", func(t *testing.T) {
+		var running, peak atomic.Int32
+		runPool(context.Background(), 10, 3, false, func(ctx context.Context, i int) (struct{}, error) {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return struct{}{}, nil
+		})
+		if got := peak.Load(); got != 3 {
+			t.Errorf("peak = %d, want 3", got)
+		}
+	})
+
+	t.Run("canceled context skips the jobs not started", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_, errs := runPool(ctx, 3, 1, false, func(ctx context.Context, i int) (int, error) {
+			cancel()
+			return i, nil
+		})
+		if errs[0] != nil {
+			t.Errorf("errs[0] = %v, want nil", errs[0])
+		}
+		for _, err := range errs[1:] {
+			if !errors.Is(err, errSkipped) || !errors.Is(err, context.Canceled) {
+				t.Errorf("err = %v, want skipped and canceled", err)
+			}
+		}
+	})
+}
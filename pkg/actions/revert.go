@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// revertUploads undoes the uploads in reverse order after a later step
+// failed, skipping the nil ones of the clients that uploaded nothing, so
+// that running optruck again starts from the same items. Each revert is
+// confirmed by confirm, if any, and the final state of every item is
+// reported to out. The reverts still run when ctx has been canceled, as an
+// interrupt is one of the failures they recover from.
+func revertUploads(ctx context.Context, clients []op.ItemClient, uploads []*op.Upload, confirm func(message string) (bool, error), out io.Writer) {
+	if out == nil {
+		out = io.Discard
+	}
+	ctx = context.WithoutCancel(ctx)
+	for i := len(uploads) - 1; i >= 0; i-- {
+		client, upload := clients[i], uploads[i]
+		if upload == nil {
+			continue
+		}
+
+		// the fields the upload added are not removed, and they hold the
+		// new secrets, so the user is told to
+		added := ""
+		if labels := upload.AddedFields(); len(labels) > 0 {
+			added = fmt.Sprintf(" The fields added by this run (%s) are kept with their new values, remove them in 1Password if they should not be there.", strings.Join(labels, ", "))
+		}
+		message := fmt.Sprintf("Item %s was overwritten in vault %s, but optruck failed afterwards. Restore its previous fields?%s", upload.ItemName, upload.VaultName, added)
+		if upload.Created() {
+			message = fmt.Sprintf("Item %s was created in vault %s, but optruck failed afterwards. Archive it?", upload.ItemName, upload.VaultName)
+		}
+		revert := true
+		if confirm != nil {
+			ok, err := confirm(message)
+			if err != nil {
+				slog.Error("failed to confirm the revert", "item", upload.ItemName, "error", err)
+			}
+			revert = ok && err == nil
+		}
+
+		if !revert {
+			if upload.Created() {
+				fmt.Fprintf(out, "Kept item %s in vault %s. Run optruck again with --overwrite to write the templates.\n", upload.ItemName, upload.VaultName)
+			} else {
+				fmt.Fprintf(out, "Kept the new fields of item %s in vault %s. Run `optruck rollback %s --to-version %d` to restore the previous ones.\n", upload.ItemName, upload.VaultName, upload.ItemName, upload.Previous.Version)
+			}
+			continue
+		}
+
+		if err := client.RevertUpload(ctx, upload); err != nil {
+			slog.Error("failed to revert the upload", "item", upload.ItemName, "vault", upload.VaultName, "error", err)
+			fmt.Fprintf(out, "Failed to revert item %s in vault %s: %v. Please check the item in 1Password.\n", upload.ItemName, upload.VaultName, err)
+			continue
+		}
+		if upload.Created() {
+			fmt.Fprintf(out, "Archived item %s created in vault %s.\n", upload.ItemName, upload.VaultName)
+		} else {
+			fmt.Fprintf(out, "Restored the previous fields of item %s in vault %s.%s\n", upload.ItemName, upload.VaultName, added)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package actions
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+	"github.com/yammerjp/optruck/pkg/op"
+
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestRevertUploads(t *testing.T) {
+	newClients := func(e exec.Interface) []op.ItemClient {
+		executor := op.WithExecutor(utilExec.NewExecutor(e))
+		return []op.ItemClient{
+			*op.NewItemClient("my.1password.com", "dev", "my-app", executor),
+			*op.NewItemClient("my.1password.com", "prod", "my-app", executor),
+		}
+	}
+	uploads := []*op.Upload{
+		{SecretReference: &op.SecretReference{VaultName: "dev", ItemName: "my-app", ItemID: "dev-id"}},
+		{SecretReference: &op.SecretReference{VaultName: "prod", ItemName: "my-app", ItemID: "prod-id"}, Previous: &op.ItemResponse{Version: 4}},
+	}
+
+	t.Run("kept", func(t *testing.T) {
+		fakeExec := &testingexec.FakeExec{}
+		var messages []string
+		var out bytes.Buffer
+
+		revertUploads(context.Background(), newClients(fakeExec), uploads, func(message string) (bool, error) {
+			messages = append(messages, message)
+			return false, nil
+		}, &out)
+
+		wantMessages := []string{
+			"Item my-app was overwritten in vault prod, but optruck failed afterwards. Restore its previous fields?",
+			"Item my-app was created in vault dev, but optruck failed afterwards. Archive it?",
+		}
+		if !reflect.DeepEqual(messages, wantMessages) {
+			t.Errorf("messages = %q, want %q", messages, wantMessages)
+		}
+		wantOut := "Kept the new fields of item my-app in vault prod. Run `optruck rollback my-app --to-version 4` to restore the previous ones.\n" +
+			"Kept item my-app in vault dev. Run optruck again with --overwrite to write the templates.\n"
+		if got := out.String(); got != wantOut {
+			t.Errorf("out = %q, want %q", got, wantOut)
+		}
+		if fakeExec.CommandCalls != 0 {
+			t.Errorf("expected no commands, got %d", fakeExec.CommandCalls)
+		}
+	})
+
+	t.Run("archive the created item", func(t *testing.T) {
+		wantArgs := []string{"item", "delete", "dev-id", "--archive", "--account", "my.1password.com", "--vault", "dev", "--format", "json"}
+		fakeExec := &testingexec.FakeExec{
+			CommandScript: []testingexec.FakeCommandAction{
+				func(cmd string, args ...string) exec.Cmd {
+					if !reflect.DeepEqual(args, wantArgs) {
+						t.Errorf("args = %v, want %v", args, wantArgs)
+					}
+					return &testingexec.FakeCmd{
+						RunScript: []testingexec.FakeAction{
+							func() ([]byte, []byte, error) { return nil, nil, nil },
+						},
+					}
+				},
+			},
+		}
+		var out bytes.Buffer
+
+		revertUploads(context.Background(), newClients(fakeExec)[:1], uploads[:1], nil, &out)
+
+		if got, want := out.String(), "Archived item my-app created in vault dev.\n"; got != want {
+			t.Errorf("out = %q, want %q", got, want)
+		}
+		if fakeExec.CommandCalls != 1 {
+			t.Errorf("expected 1 command, got %d", fakeExec.CommandCalls)
+		}
+	})
+
+	t.Run("tell the added fields are kept", func(t *testing.T) {
+		upload := &op.Upload{
+			SecretReference: &op.SecretReference{VaultName: "prod", ItemName: "my-app", ItemID: "prod-id", FieldLabels: []string{"FOO", "NEW"}},
+			Previous:        &op.ItemResponse{Version: 4, Fields: []op.ItemResponseField{{Label: "FOO", Value: "old"}}},
+		}
+		var messages []string
+
+		revertUploads(context.Background(), newClients(&testingexec.FakeExec{})[1:], []*op.Upload{upload}, func(message string) (bool, error) {
+			messages = append(messages, message)
+			return false, nil
+		}, nil)
+
+		want := []string{"Item my-app was overwritten in vault prod, but optruck failed afterwards. Restore its previous fields? The fields added by this run (NEW) are kept with their new values, remove them in 1Password if they should not be there."}
+		if !reflect.DeepEqual(messages, want) {
+			t.Errorf("messages = %q, want %q", messages, want)
+		}
+	})
+
+	t.Run("skip the vaults without an upload", func(t *testing.T) {
+		fakeExec := &testingexec.FakeExec{}
+		var out bytes.Buffer
+
+		revertUploads(context.Background(), newClients(fakeExec), []*op.Upload{nil, uploads[1]}, func(message string) (bool, error) {
+			return false, nil
+		}, &out)
+
+		wantOut := "Kept the new fields of item my-app in vault prod. Run `optruck rollback my-app --to-version 4` to restore the previous ones.\n"
+		if got := out.String(); got != wantOut {
+			t.Errorf("out = %q, want %q", got, wantOut)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package actions
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+)
+
+// RollbackConfig restores a version of an item and writes its reference to
+// every dest. Like MirrorConfig, the templates are checked before the item
+// changes.
+type RollbackConfig struct {
+	OpItemClient op.ItemClient
+	Version      int
+	Dests        []output.Dest
+	Force        bool
+	Confirmation func() error
+	Out          io.Writer
+}
+
+func (config RollbackConfig) Run(ctx context.Context) error {
+	slog.Debug("Starting rollback action for item", "item", config.OpItemClient.ItemName, "version", config.Version)
+
+	if err := config.Confirmation(); err != nil {
+		slog.Error("failed to confirm", "error", err)
+		return err
+	}
+
+	pending, err := config.OpItemClient.PrepareRollback(ctx, config.Version)
+	if err != nil {
+		slog.Error("failed to roll back the 1Password item", "error", err)
+		return err
+	}
+	ref, err := pendingReference(ctx, config.OpItemClient, config.Dests, pending)
+	if err != nil {
+		slog.Error("failed to validate the secret reference", "error", err)
+		return err
+	}
+	if err := checkDests(config.Dests, ref, config.Out, config.Force); err != nil {
+		slog.Error("failed to check the templates before the rollback", "error", err)
+		return err
+	}
+
+	upload, err := config.OpItemClient.CommitUpload(ctx, pending)
+	if err != nil {
+		slog.Error("failed to roll back the 1Password item", "error", err)
+		return err
+	}
+	slog.Debug("Rolled back the 1Password item successfully")
+
+	if err := writeDests(config.Dests, upload.SecretReference, config.Out, config.Force); err != nil {
+		return err
+	}
+
+	slog.Debug("Rollback action completed successfully")
+	return nil
+}
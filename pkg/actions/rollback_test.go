@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+	"github.com/yammerjp/optruck/pkg/op"
+	"github.com/yammerjp/optruck/pkg/output"
+
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+func TestRollbackChecksTemplatesBeforeEdit(t *testing.T) {
+	template := filepath.Join(t.TempDir(), ".env.1password")
+	existing := "FOO=\"{{op://old-vault/old-item/FOO}}\"\n"
+	if err := os.WriteFile(template, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := []string{
+		`{"id": "item-id", "title": "my-app", "version": 2, "vault": {"id": "dev-id", "name": "dev"},
+		  "fields": [{"id": "FOO", "type": "CONCEALED", "label": "FOO", "value": "foo2"}]}`,
+		`[{"id": "snapshot-1"}]`,
+		`{"id": "snapshot-1", "fields": [
+		  {"id": "notesPlain", "type": "STRING", "purpose": "NOTES", "label": "notesPlain", "value": "{\"item_id\":\"item-id\",\"version\":1}"},
+		  {"id": "FOO", "type": "CONCEALED", "label": "FOO", "value": "foo1"}]}`,
+	}
+	var gotCmds [][]string
+	fakeExec := &testingexec.FakeExec{}
+	for _, stdout := range outputs {
+		fakeExec.CommandScript = append(fakeExec.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			gotCmds = append(gotCmds, args[:2])
+			return &testingexec.FakeCmd{RunScript: []testingexec.FakeAction{
+				func() ([]byte, []byte, error) { return []byte(stdout), nil, nil },
+			}}
+		})
+	}
+	config := RollbackConfig{
+		OpItemClient: *op.NewItemClient("my.1password.com", "dev", "my-app", op.WithExecutor(utilExec.NewExecutor(fakeExec))),
+		Version:      1,
+		Dests:        []output.Dest{&output.EnvTemplateDest{Path: template}},
+		Confirmation: func() error { return nil },
+	}
+
+	err := config.Run(context.Background())
+
+	if !errors.Is(err, output.ErrTemplateChanged) {
+		t.Errorf("Run() error = %v, want %v", err, output.ErrTemplateChanged)
+	}
+	wantCmds := [][]string{{"item", "get"}, {"item", "list"}, {"item", "get"}}
+	if !reflect.DeepEqual(gotCmds, wantCmds) {
+		t.Errorf("op commands = %v, want %v, without editing the item", gotCmds, wantCmds)
+	}
+	if got, _ := os.ReadFile(template); string(got) != existing {
+		t.Errorf("template = %q, want %q", got, existing)
+	}
+}
@@ -0,0 +1,166 @@
+package datasources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeSource reads the secrets of a service in a docker compose file, from
+// both its env_file and environment entries. Like docker compose, environment
+// takes precedence over env_file, and later env files over earlier ones.
+type ComposeSource struct {
+	Path    string
+	Service string
+}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	EnvFile     composeEnvFiles    `yaml:"env_file"`
+	Environment composeEnvironment `yaml:"environment"`
+}
+
+type composeEnvFile struct {
+	Path     string `yaml:"path"`
+	Required *bool  `yaml:"required"`
+}
+
+// composeEnvFiles accepts the short syntax (a string or a list of strings) and
+// the long syntax (a list of {path, required}) of env_file.
+type composeEnvFiles []composeEnvFile
+
+func (f *composeEnvFiles) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*f = composeEnvFiles{{Path: node.Value}}
+		return nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("line %d: env_file must be a string or a list", node.Line)
+	}
+	for _, item := range node.Content {
+		var envFile composeEnvFile
+		if item.Kind == yaml.ScalarNode {
+			envFile.Path = item.Value
+		} else if err := item.Decode(&envFile); err != nil {
+			return err
+		}
+		*f = append(*f, envFile)
+	}
+	return nil
+}
+
+// composeEnvironment accepts both the map and the list ("KEY=VALUE") syntax of
+// environment. Keys without a value are taken from the shell by docker compose,
+// so they are not secrets of the compose file and are skipped.
+type composeEnvironment map[string]string
+
+func (e *composeEnvironment) UnmarshalYAML(node *yaml.Node) error {
+	env := composeEnvironment{}
+	switch node.Kind {
+	case yaml.MappingNode:
+		var values map[string]*string
+		if err := node.Decode(&values); err != nil {
+			return err
+		}
+		for k, v := range values {
+			if v == nil {
+				slog.Debug("skipping environment variable without value", "key", k)
+				continue
+			}
+			env[k] = *v
+		}
+	case yaml.SequenceNode:
+		var entries []string
+		if err := node.Decode(&entries); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			k, v, ok := strings.Cut(entry, "=")
+			if !ok {
+				slog.Debug("skipping environment variable without value", "key", k)
+				continue
+			}
+			env[k] = v
+		}
+	default:
+		return fmt.Errorf("line %d: environment must be a map or a list", node.Line)
+	}
+	*e = env
+	return nil
+}
+
+func (s *ComposeSource) FetchSecrets(ctx context.Context) (map[string]string, error) {
+	content, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the compose file: %w", err)
+	}
+	var compose composeFile
+	if err := yaml.Unmarshal(content, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse the compose file %s: %w", s.Path, err)
+	}
+	service, ok := compose.Services[s.Service]
+	if !ok {
+		return nil, fmt.Errorf("service %s is not found in %s, please specify the service with --compose-service option", s.Service, s.Path)
+	}
+
+	secrets := map[string]string{}
+	for _, envFile := range service.EnvFile {
+		path := envFile.Path
+		if !filepath.IsAbs(path) {
+			// relative to the compose file, as docker compose does
+			path = filepath.Join(filepath.Dir(s.Path), path)
+		}
+		values, err := godotenv.Read(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) && envFile.Required != nil && !*envFile.Required {
+				slog.Debug("skipping optional env file", "path", path)
+				continue
+			}
+			return nil, fmt.Errorf("failed to read env_file %s of service %s: %w", envFile.Path, s.Service, err)
+		}
+		for k, v := range values {
+			secrets[k] = v
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(service.Environment)) {
+		v, err := unescapeCompose(service.Environment[k])
+		if err != nil {
+			return nil, fmt.Errorf("environment variable %s of service %s: %w", k, s.Service, err)
+		}
+		secrets[k] = v
+	}
+	return secrets, nil
+}
+
+// unescapeCompose returns value as docker compose passes it to the service.
+// Compose replaces $VAR and ${VAR} with the values of its own environment,
+// which optruck does not know, so they are rejected like the expansions of
+// a shell file rather than uploaded as written; $$ is an escaped $.
+func unescapeCompose(value string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+1 < len(value) && value[i+1] == '$' {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		return "", fmt.Errorf("interpolation is not supported, please write the value itself in the compose file and escape $ as $$")
+	}
+	return b.String(), nil
+}
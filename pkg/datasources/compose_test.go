@@ -0,0 +1,165 @@
+package datasources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestComposeSource_FetchSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+		files   map[string]string
+		service string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "environment map",
+			compose: `services:
+  app:
+    image: app
+    environment:
+      DB_USER: app
+      DB_PORT: 5432
+      FROM_SHELL:
+`,
+			service: "app",
+			want:    map[string]string{"DB_USER": "app", "DB_PORT": "5432"},
+		},
+		{
+			name: "environment list",
+			compose: `services:
+  app:
+    environment:
+      - DB_USER=app
+      - DB_PASS=p=ss
+      - FROM_SHELL
+`,
+			service: "app",
+			want:    map[string]string{"DB_USER": "app", "DB_PASS": "p=ss"},
+		},
+		{
+			name: "escaped dollar",
+			compose: `services:
+  app:
+    environment:
+      DB_PASS: pa$$word
+      PRICE: $$5
+`,
+			service: "app",
+			want:    map[string]string{"DB_PASS": "pa$word", "PRICE": "$5"},
+		},
+		{
+			name: "braced interpolation",
+			compose: `services:
+  app:
+    environment:
+      DB_PASS: ${DB_PASSWORD}
+`,
+			service: "app",
+			wantErr: true,
+		},
+		{
+			name: "interpolation with a default",
+			compose: `services:
+  app:
+    environment:
+      - DB_PASS=${DB_PASSWORD:-x}
+`,
+			service: "app",
+			wantErr: true,
+		},
+		{
+			name: "unbraced interpolation",
+			compose: `services:
+  app:
+    environment:
+      DB_PASS: pa$word
+`,
+			service: "app",
+			wantErr: true,
+		},
+		{
+			name: "env_file string overridden by environment",
+			compose: `services:
+  app:
+    env_file: app.env
+    environment:
+      DB_USER: override
+`,
+			files:   map[string]string{"app.env": "DB_USER=app\nDB_PASS=secret\n"},
+			service: "app",
+			want:    map[string]string{"DB_USER": "override", "DB_PASS": "secret"},
+		},
+		{
+			name: "env_file long syntax",
+			compose: `services:
+  app:
+    env_file:
+      - path: ./default.env
+      - path: ./local.env
+        required: false
+      - override.env
+`,
+			files: map[string]string{
+				"default.env":  "A=1\nB=1\n",
+				"override.env": "B=2\n",
+			},
+			service: "app",
+			want:    map[string]string{"A": "1", "B": "2"},
+		},
+		{
+			name: "required env_file missing",
+			compose: `services:
+  app:
+    env_file: missing.env
+`,
+			service: "app",
+			wantErr: true,
+		},
+		{
+			name: "service not found",
+			compose: `services:
+  db:
+    environment:
+      A: "1"
+`,
+			service: "app",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			compose: "services: [",
+			service: "app",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "compose.yaml")
+			if err := os.WriteFile(path, []byte(tt.compose), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			source := &ComposeSource{Path: path, Service: tt.service}
+			got, err := source.FetchSecrets(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FetchSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FetchSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
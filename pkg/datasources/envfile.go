@@ -1,6 +1,8 @@
 package datasources
 
 import (
+	"context"
+
 	"github.com/joho/godotenv"
 )
 
@@ -8,6 +10,6 @@ type EnvFileSource struct {
 	Path string
 }
 
-func (e *EnvFileSource) FetchSecrets() (map[string]string, error) {
+func (e *EnvFileSource) FetchSecrets(ctx context.Context) (map[string]string, error) {
 	return godotenv.Read(e.Path)
 }
@@ -1,6 +1,7 @@
 package datasources
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -17,7 +18,7 @@ func TestEnvFileSource_FetchSecrets(t *testing.T) {
 	}
 
 	source := &EnvFileSource{Path: envPath}
-	secrets, err := source.FetchSecrets()
+	secrets, err := source.FetchSecrets(context.Background())
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -34,7 +35,7 @@ func TestEnvFileSource_FetchSecrets(t *testing.T) {
 
 func TestEnvFileSource_FetchSecrets_FileNotFound(t *testing.T) {
 	source := &EnvFileSource{Path: "nonexistent.env"}
-	secrets, err := source.FetchSecrets()
+	secrets, err := source.FetchSecrets(context.Background())
 
 	if err == nil {
 		t.Error("expected error, got nil")
@@ -1,6 +1,7 @@
 package datasources
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 
@@ -27,7 +28,7 @@ type K8sSecretSource struct {
 	Client     *kube.Client
 }
 
-func (s *K8sSecretSource) FetchSecrets() (map[string]string, error) {
+func (s *K8sSecretSource) FetchSecrets(ctx context.Context) (map[string]string, error) {
 	if err := validateDNS1123Subdomain(s.Namespace); err != nil {
 		return nil, fmt.Errorf("invalid namespace name, please specify a valid namespace name with --k8s-namespace option: %w", err)
 	}
@@ -35,7 +36,7 @@ func (s *K8sSecretSource) FetchSecrets() (map[string]string, error) {
 		return nil, fmt.Errorf("invalid secret name, please specify a valid secret name with --k8s-secret option: %w", err)
 	}
 
-	secrets, err := s.Client.GetSecret(s.Namespace, s.SecretName)
+	secrets, err := s.Client.GetSecret(ctx, s.Namespace, s.SecretName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch secrets from Kubernetes: %w. Please check the namespace and secret name, and try again.", err)
 	}
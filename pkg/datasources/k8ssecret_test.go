@@ -1,6 +1,8 @@
 package datasources
 
 import (
+	"context"
+
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 
 	"errors"
@@ -189,8 +191,7 @@ func TestK8sSecretSource_FetchSecrets(t *testing.T) {
 			}
 			fakeExec.CommandScript = []testingexec.FakeCommandAction{cmdAction}
 
-			client := &kube.Client{}
-			utilExec.SetExec(fakeExec)
+			client := kube.NewClient(kube.WithExecutor(utilExec.NewExecutor(fakeExec)))
 
 			source := &K8sSecretSource{
 				Namespace:  tt.namespace,
@@ -198,7 +199,7 @@ func TestK8sSecretSource_FetchSecrets(t *testing.T) {
 				Client:     client,
 			}
 
-			got, err := source.FetchSecrets()
+			got, err := source.FetchSecrets(context.Background())
 
 			// コマンドと引数の検証（バリデーションエラーの場合はスキップ）
 			if !tt.skipCommandTest {
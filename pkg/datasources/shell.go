@@ -0,0 +1,203 @@
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ShellScriptSource reads the variables assigned by a shell script of
+// `export KEY=value` (or plain `KEY=value`) lines, skipping the variables
+// exported without a value by `export KEY`. Values follow the POSIX
+// shell quoting rules; parameter expansion and command substitution are
+// rejected, since they cannot be resolved without running the script.
+type ShellScriptSource struct {
+	Path string
+}
+
+func (s *ShellScriptSource) FetchSecrets(ctx context.Context) (map[string]string, error) {
+	content, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the shell script: %w", err)
+	}
+	secrets, err := parseShellScript(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the shell script %s: %w", s.Path, err)
+	}
+	return secrets, nil
+}
+
+type shellScanner struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func (s *shellScanner) eof() bool {
+	return s.pos >= len(s.src)
+}
+
+func (s *shellScanner) peek() rune {
+	return s.src[s.pos]
+}
+
+func (s *shellScanner) next() rune {
+	c := s.src[s.pos]
+	s.pos++
+	if c == '\n' {
+		s.line++
+	}
+	return c
+}
+
+func isShellBlank(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+func isShellNameChar(c rune, first bool) bool {
+	return c == '_' || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || (!first && '0' <= c && c <= '9')
+}
+
+func parseShellScript(content string) (map[string]string, error) {
+	s := &shellScanner{src: []rune(content), line: 1}
+	secrets := map[string]string{}
+	for {
+		// start of a statement
+		for !s.eof() && (isShellBlank(s.peek()) || s.peek() == '\n' || s.peek() == ';') {
+			s.next()
+		}
+		if s.eof() {
+			return secrets, nil
+		}
+		if s.peek() == '#' {
+			s.skipComment()
+			continue
+		}
+
+		words := 0
+		exported := false
+		for !s.eof() && s.peek() != '\n' && s.peek() != ';' {
+			if isShellBlank(s.peek()) {
+				s.next()
+				continue
+			}
+			if s.peek() == '#' {
+				s.skipComment()
+				break
+			}
+			line := s.line
+			key := s.readName()
+			if words == 0 && key == "export" && !s.eof() && isShellBlank(s.peek()) {
+				exported = true
+				words++
+				continue
+			}
+			// `export KEY` exports a variable the script does not assign
+			if exported && key != "" && (s.eof() || isShellBlank(s.peek()) || s.peek() == '\n' || s.peek() == ';') {
+				words++
+				continue
+			}
+			if key == "" || s.eof() || s.peek() != '=' {
+				return nil, fmt.Errorf("line %d: only `export KEY=value` and `KEY=value` statements are supported", line)
+			}
+			s.next()
+			value, err := s.readValue()
+			if err != nil {
+				return nil, err
+			}
+			secrets[key] = value
+			words++
+		}
+	}
+}
+
+func (s *shellScanner) skipComment() {
+	for !s.eof() && s.peek() != '\n' {
+		s.next()
+	}
+}
+
+func (s *shellScanner) readName() string {
+	start := s.pos
+	for !s.eof() && isShellNameChar(s.peek(), s.pos == start) {
+		s.next()
+	}
+	return string(s.src[start:s.pos])
+}
+
+// readValue reads a word, which ends at an unquoted blank, newline or ';'.
+func (s *shellScanner) readValue() (string, error) {
+	value := &strings.Builder{}
+	for !s.eof() {
+		c := s.peek()
+		switch {
+		case isShellBlank(c), c == '\n', c == ';':
+			return value.String(), nil
+		case c == '\'':
+			line := s.line
+			s.next()
+			for {
+				if s.eof() {
+					return "", fmt.Errorf("line %d: unterminated single quote", line)
+				}
+				c := s.next()
+				if c == '\'' {
+					break
+				}
+				value.WriteRune(c)
+			}
+		case c == '"':
+			line := s.line
+			s.next()
+			for {
+				if s.eof() {
+					return "", fmt.Errorf("line %d: unterminated double quote", line)
+				}
+				c := s.peek()
+				if c == '"' {
+					s.next()
+					break
+				}
+				if err := s.checkExpansion(); err != nil {
+					return "", err
+				}
+				s.next()
+				if c == '\\' && !s.eof() && strings.ContainsRune("$`\"\\\n", s.peek()) {
+					if e := s.next(); e != '\n' {
+						value.WriteRune(e)
+					}
+					continue
+				}
+				value.WriteRune(c)
+			}
+		case c == '\\':
+			s.next()
+			if s.eof() {
+				return value.String(), nil
+			}
+			if e := s.next(); e != '\n' {
+				value.WriteRune(e)
+			}
+		default:
+			if err := s.checkExpansion(); err != nil {
+				return "", err
+			}
+			value.WriteRune(s.next())
+		}
+	}
+	return value.String(), nil
+}
+
+func (s *shellScanner) checkExpansion() error {
+	c := s.peek()
+	if c == '`' {
+		return fmt.Errorf("line %d: command substitution is not supported, please quote the value with single quotes", s.line)
+	}
+	if c == '$' && s.pos+1 < len(s.src) {
+		if n := s.src[s.pos+1]; n == '{' || n == '(' || isShellNameChar(n, true) || strings.ContainsRune("@*#?$!-0123456789", n) {
+			return fmt.Errorf("line %d: parameter expansion and command substitution are not supported, please quote the value with single quotes", s.line)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package datasources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseShellScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "export and plain assignments",
+			content: `#!/bin/sh
+# database
+export DB_USER=app
+DB_HOST=localhost # inline comment
+export A=1 B=2; C=3
+`,
+			want: map[string]string{"DB_USER": "app", "DB_HOST": "localhost", "A": "1", "B": "2", "C": "3"},
+		},
+		{
+			name: "quoting",
+			content: `export SINGLE='a $b "c" \n'
+export DOUBLE="a \$b \"c\" \\ \n"
+export ESCAPED=a\ b\'c
+export CONCAT='a'"b"c
+export EMPTY=
+export EMPTY_QUOTED=""
+export HASH=a#b
+export DOLLAR="cost: $"
+`,
+			want: map[string]string{
+				"SINGLE":       `a $b "c" \n`,
+				"DOUBLE":       `a $b "c" \ \n`,
+				"ESCAPED":      `a b'c`,
+				"CONCAT":       "abc",
+				"EMPTY":        "",
+				"EMPTY_QUOTED": "",
+				"HASH":         "a#b",
+				"DOLLAR":       "cost: $",
+			},
+		},
+		{
+			name:    "multiline values",
+			content: "export CERT='line1\nline2'\nexport LONG=\"a\\\nb\"\nexport CONT=c\\\nd\n",
+			want:    map[string]string{"CERT": "line1\nline2", "LONG": "ab", "CONT": "cd"},
+		},
+		{
+			name:    "parameter expansion",
+			content: "export PATH=$PATH:/usr/local/bin\n",
+			wantErr: true,
+		},
+		{
+			name:    "parameter expansion in double quotes",
+			content: `export URL="postgres://${DB_USER}@localhost"`,
+			wantErr: true,
+		},
+		{
+			name:    "command substitution",
+			content: "export TOKEN=`cat token`\n",
+			wantErr: true,
+		},
+		{
+			name:    "export without a value",
+			content: "export PATH\nexport A=1 HOME; export B\n",
+			want:    map[string]string{"A": "1"},
+		},
+		{
+			name:    "unsupported statement",
+			content: "if true; then export A=1; fi\n",
+			wantErr: true,
+		},
+		{
+			name:    "command",
+			content: "A=1 run\n",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			content: "export A='abc\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseShellScript(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseShellScript() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseShellScript() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellScriptSource_FetchSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "env.sh")
+	if err := os.WriteFile(path, []byte("export KEY1=value1\nexport KEY2='value 2'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &ShellScriptSource{Path: path}
+	secrets, err := source.FetchSecrets(context.Background())
+	if err != nil {
+		t.Fatalf("FetchSecrets() error = %v", err)
+	}
+	want := map[string]string{"KEY1": "value1", "KEY2": "value 2"}
+	if !reflect.DeepEqual(secrets, want) {
+		t.Errorf("FetchSecrets() = %v, want %v", secrets, want)
+	}
+
+	if _, err := (&ShellScriptSource{Path: "nonexistent.sh"}).FetchSecrets(context.Background()); err == nil {
+		t.Error("FetchSecrets() expected error for a missing file")
+	}
+}
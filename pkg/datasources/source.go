@@ -1,8 +1,13 @@
 package datasources
 
+import "context"
+
 type Source interface {
-	FetchSecrets() (map[string]string, error)
+	FetchSecrets(ctx context.Context) (map[string]string, error)
 }
 
 var _ Source = (*EnvFileSource)(nil)
 var _ Source = (*K8sSecretSource)(nil)
+var _ Source = (*ComposeSource)(nil)
+var _ Source = (*ShellScriptSource)(nil)
+var _ Source = (*SystemdEnvFileSource)(nil)
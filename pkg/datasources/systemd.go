@@ -0,0 +1,193 @@
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SystemdEnvFileSource reads a file for systemd's EnvironmentFile=, following
+// the quoting rules of systemd.exec(5): lines starting with '#' or ';' are
+// comments, values may be single- or double-quoted, and a backslash at the
+// end of a line continues the value on the next one.
+type SystemdEnvFileSource struct {
+	Path string
+}
+
+func (s *SystemdEnvFileSource) FetchSecrets(ctx context.Context) (map[string]string, error) {
+	content, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the systemd environment file: %w", err)
+	}
+	secrets, err := parseSystemdEnvFile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the systemd environment file %s: %w", s.Path, err)
+	}
+	return secrets, nil
+}
+
+type systemdEnvState int
+
+const (
+	systemdPreKey systemdEnvState = iota
+	systemdKey
+	systemdPreValue
+	systemdValue
+	systemdValueEscape
+	systemdSingleQuote
+	systemdDoubleQuote
+	systemdDoubleQuoteEscape
+	systemdComment
+	systemdCommentEscape
+)
+
+// parseSystemdEnvFile follows the state machine of systemd's
+// parse_env_file_internal(), which skips a line without '='. Unlike systemd,
+// it rejects an invalid variable name instead of ignoring it, so that no
+// secret is dropped without a word.
+func parseSystemdEnvFile(content string) (map[string]string, error) {
+	secrets := map[string]string{}
+	state := systemdPreKey
+	line := 1
+	key := &strings.Builder{}
+	value := &strings.Builder{}
+	// length of value without its trailing unquoted whitespace
+	valueLen := 0
+
+	push := func() error {
+		k := strings.TrimRight(key.String(), " \t\r")
+		if !isValidEnvKey(k) {
+			return fmt.Errorf("line %d: invalid variable name %q", line, k)
+		}
+		secrets[k] = value.String()[:valueLen]
+		key.Reset()
+		value.Reset()
+		valueLen = 0
+		return nil
+	}
+	appendValue := func(c rune, quoted bool) {
+		value.WriteRune(c)
+		if quoted || !isShellBlank(c) {
+			valueLen = value.Len()
+		}
+	}
+
+	for _, c := range content {
+		switch state {
+		case systemdPreKey:
+			switch {
+			case c == '#' || c == ';':
+				state = systemdComment
+			case c == '\n' || isShellBlank(c):
+			default:
+				state = systemdKey
+				key.WriteRune(c)
+			}
+		case systemdKey:
+			switch c {
+			case '\n':
+				state = systemdPreKey
+				key.Reset()
+			case '=':
+				state = systemdPreValue
+			default:
+				key.WriteRune(c)
+			}
+		case systemdPreValue:
+			switch {
+			case c == '\n':
+				state = systemdPreKey
+				if err := push(); err != nil {
+					return nil, err
+				}
+			case c == '\'':
+				state = systemdSingleQuote
+			case c == '"':
+				state = systemdDoubleQuote
+			case c == '\\':
+				state = systemdValueEscape
+			case isShellBlank(c):
+			default:
+				state = systemdValue
+				appendValue(c, false)
+			}
+		case systemdValue:
+			switch c {
+			case '\n':
+				state = systemdPreKey
+				if err := push(); err != nil {
+					return nil, err
+				}
+			case '\\':
+				state = systemdValueEscape
+			default:
+				appendValue(c, false)
+			}
+		case systemdValueEscape:
+			state = systemdValue
+			if c != '\n' {
+				appendValue(c, true)
+			}
+		case systemdSingleQuote:
+			if c == '\'' {
+				state = systemdPreValue
+			} else {
+				appendValue(c, true)
+			}
+		case systemdDoubleQuote:
+			switch c {
+			case '"':
+				state = systemdPreValue
+			case '\\':
+				state = systemdDoubleQuoteEscape
+			default:
+				appendValue(c, true)
+			}
+		case systemdDoubleQuoteEscape:
+			state = systemdDoubleQuote
+			switch {
+			case strings.ContainsRune("\"\\`$", c):
+				appendValue(c, true)
+			case c == '\n':
+			default:
+				appendValue('\\', true)
+				appendValue(c, true)
+			}
+		case systemdComment:
+			switch c {
+			case '\\':
+				state = systemdCommentEscape
+			case '\n':
+				state = systemdPreKey
+			}
+		case systemdCommentEscape:
+			state = systemdComment
+		}
+		if c == '\n' {
+			line++
+		}
+	}
+
+	switch state {
+	case systemdSingleQuote, systemdDoubleQuote, systemdDoubleQuoteEscape:
+		return nil, fmt.Errorf("line %d: unterminated quote", line)
+	case systemdPreValue, systemdValue, systemdValueEscape:
+		if err := push(); err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
+
+func isValidEnvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, c := range key {
+		if !isShellNameChar(c, i == 0) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package datasources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSystemdEnvFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "comments and whitespace",
+			content: `# comment
+; also a comment
+  DB_USER = app  
+DB_HOST=localhost # not a comment
+EMPTY=
+`,
+			want: map[string]string{"DB_USER": "app", "DB_HOST": "localhost # not a comment", "EMPTY": ""},
+		},
+		{
+			name: "quoting",
+			content: `SINGLE='a $b "c" \n'
+DOUBLE="a \$b \"c\" \\ \n"
+ESCAPED=a\ b\'c
+CONCAT='a' "b"
+QUOTED_SPACES='  a  '
+MIDDLE=a'b'
+`,
+			want: map[string]string{
+				"SINGLE":        `a $b "c" \n`,
+				"DOUBLE":        `a $b "c" \ \n`,
+				"ESCAPED":       `a b'c`,
+				"CONCAT":        "ab",
+				"QUOTED_SPACES": "  a  ",
+				"MIDDLE":        "a'b'",
+			},
+		},
+		{
+			name:    "multiline values",
+			content: "CERT=\"line1\nline2\"\nLONG=a\\\nb\nLAST=end",
+			want:    map[string]string{"CERT": "line1\nline2", "LONG": "ab", "LAST": "end"},
+		},
+		{
+			name:    "lines without equal sign are skipped",
+			content: "A=1\nINVALID\nB=2\nLAST",
+			want:    map[string]string{"A": "1", "B": "2"},
+		},
+		{
+			name:    "invalid key",
+			content: "export A=1\n",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			content: "A='abc\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSystemdEnvFile(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSystemdEnvFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSystemdEnvFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSystemdEnvFileSource_FetchSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.env")
+	if err := os.WriteFile(path, []byte("KEY1=value1\nKEY2=\"value 2\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &SystemdEnvFileSource{Path: path}
+	secrets, err := source.FetchSecrets(context.Background())
+	if err != nil {
+		t.Fatalf("FetchSecrets() error = %v", err)
+	}
+	want := map[string]string{"KEY1": "value1", "KEY2": "value 2"}
+	if !reflect.DeepEqual(secrets, want) {
+		t.Errorf("FetchSecrets() = %v, want %v", secrets, want)
+	}
+}
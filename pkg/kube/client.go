@@ -2,6 +2,7 @@ package kube
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -9,16 +10,40 @@ import (
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 )
 
+// The reasons a kubectl command fails that the user can act on, told by the
+// API server in the stderr of kubectl.
+var (
+	ErrNotFound  = errors.New("not found in Kubernetes")
+	ErrForbidden = errors.New("forbidden by Kubernetes")
+)
+
 type Client struct {
+	executor *utilExec.Executor
+}
+
+// Option configures a client.
+type Option func(*Client)
+
+// WithExecutor runs the kubectl commands of the client with e, e.g. a fake in
+// tests, or with KUBECONFIG set for the client only. Without it, kubectl runs
+// on the host.
+func WithExecutor(e *utilExec.Executor) Option {
+	return func(c *Client) {
+		c.executor = e
+	}
 }
 
-func NewClient() *Client {
-	return &Client{}
+func NewClient(opts ...Option) *Client {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func (c *Client) GetSecret(namespace, secretName string) (map[string]string, error) {
+func (c *Client) GetSecret(ctx context.Context, namespace, secretName string) (map[string]string, error) {
 	// TODO: use k8s.io/client-go
-	cmd := utilExec.NewCommand("kubectl", "get", "secret", "-n", namespace, secretName, "-o", "jsonpath={.data}")
+	cmd := c.newCommand(ctx, "get", "secret", "-n", namespace, secretName, "-o", "jsonpath={.data}")
 	// ex: {"AWS_ACCESS_KEY_ID":"YWJjZGVmZ2hpamtsbW5vcA==","AWS_SECRET_ACCESS_KEY":"YWJjZGVmZ2hpamtsbW5vcA=="}
 	secrets := make(map[string]string)
 	if err := cmd.RunWithJSON(nil, &secrets); err != nil {
@@ -27,8 +52,8 @@ func (c *Client) GetSecret(namespace, secretName string) (map[string]string, err
 	return secrets, nil
 }
 
-func (c *Client) GetNamespaces() ([]string, error) {
-	cmd := utilExec.NewCommand("kubectl", "get", "namespaces", "-o", "jsonpath={.items[*].metadata.name}")
+func (c *Client) GetNamespaces(ctx context.Context) ([]string, error) {
+	cmd := c.newCommand(ctx, "get", "namespaces", "-o", "jsonpath={.items[*].metadata.name}")
 	stdout := &bytes.Buffer{}
 	if err := cmd.Run(nil, stdout); err != nil {
 		return nil, fmt.Errorf("failed to get namespaces with `$ kubectl get namespaces -o jsonpath={.items[*].metadata.name}`: %w", err)
@@ -40,8 +65,8 @@ func (c *Client) GetNamespaces() ([]string, error) {
 	return strings.Split(output, " "), nil
 }
 
-func (c *Client) GetSecrets(namespace string) ([]string, error) {
-	cmd := utilExec.NewCommand("kubectl", "get", "secrets", "-n", namespace, "--field-selector", "type=Opaque", "-o", "jsonpath={.items[*].metadata.name}")
+func (c *Client) GetSecrets(ctx context.Context, namespace string) ([]string, error) {
+	cmd := c.newCommand(ctx, "get", "secrets", "-n", namespace, "--field-selector", "type=Opaque", "-o", "jsonpath={.items[*].metadata.name}")
 	stdout := &bytes.Buffer{}
 	if err := cmd.Run(nil, stdout); err != nil {
 		return nil, fmt.Errorf("failed to get secrets with `$ kubectl get secrets -n %s --field-selector type=Opaque -o jsonpath={.items[*].metadata.name}`: %w", namespace, err)
@@ -52,3 +77,22 @@ func (c *Client) GetSecrets(namespace string) ([]string, error) {
 	}
 	return strings.Split(output, " "), nil
 }
+
+func (c *Client) newCommand(ctx context.Context, args ...string) utilExec.Command {
+	cmd := c.executor.Command(ctx, "kubectl", args...)
+	cmd.Classify = classifyError
+	return cmd
+}
+
+// classifyError returns the reason of a failed kubectl command from its
+// stderr, e.g. `Error from server (NotFound): secrets "app" not found`, or
+// nil if it is not known.
+func classifyError(stderr string) error {
+	switch {
+	case strings.Contains(stderr, "(NotFound)"):
+		return ErrNotFound
+	case strings.Contains(stderr, "(Forbidden)"):
+		return ErrForbidden
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
 package kube
 
 import (
+	"context"
 	"errors"
+	"os"
 	"reflect"
+	"slices"
 	"testing"
 
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
@@ -21,6 +24,7 @@ func TestGetSecret(t *testing.T) {
 		exitStatus   int
 		cmdError     error
 		expectedErr  bool
+		expectedKind error
 		expectedData map[string]string
 	}{
 		{
@@ -43,12 +47,22 @@ func TestGetSecret(t *testing.T) {
 			expectedErr: true,
 		},
 		{
-			name:        "secret not found",
-			namespace:   "default",
-			secretName:  "nonexistent",
-			mockStderr:  "Error from server (NotFound): secrets \"nonexistent\" not found",
-			exitStatus:  1,
-			expectedErr: true,
+			name:         "secret not found",
+			namespace:    "default",
+			secretName:   "nonexistent",
+			mockStderr:   "Error from server (NotFound): secrets \"nonexistent\" not found",
+			exitStatus:   1,
+			expectedErr:  true,
+			expectedKind: ErrNotFound,
+		},
+		{
+			name:         "secret forbidden",
+			namespace:    "kube-system",
+			secretName:   "mysecret",
+			mockStderr:   "Error from server (Forbidden): secrets \"mysecret\" is forbidden: User \"dev\" cannot get resource \"secrets\" in API group \"\" in the namespace \"kube-system\"",
+			exitStatus:   1,
+			expectedErr:  true,
+			expectedKind: ErrForbidden,
 		},
 		{
 			name:        "kubectl not found",
@@ -107,9 +121,8 @@ func TestGetSecret(t *testing.T) {
 				},
 			}
 
-			client := NewClient()
-			utilExec.SetExec(fakeExec)
-			data, err := client.GetSecret(tt.namespace, tt.secretName)
+			client := NewClient(WithExecutor(utilExec.NewExecutor(fakeExec)))
+			data, err := client.GetSecret(context.Background(), tt.namespace, tt.secretName)
 
 			if tt.expectedErr && err == nil {
 				t.Error("expected error but got nil")
@@ -117,6 +130,9 @@ func TestGetSecret(t *testing.T) {
 			if !tt.expectedErr && err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
+			if tt.expectedKind != nil && !errors.Is(err, tt.expectedKind) {
+				t.Errorf("expected error to wrap %v, got %v", tt.expectedKind, err)
+			}
 			if !tt.expectedErr && !reflect.DeepEqual(data, tt.expectedData) {
 				t.Errorf("expected %v, got %v", tt.expectedData, data)
 			}
@@ -198,9 +214,8 @@ func TestGetNamespaces(t *testing.T) {
 				},
 			}
 
-			client := NewClient()
-			utilExec.SetExec(fakeExec)
-			names, err := client.GetNamespaces()
+			client := NewClient(WithExecutor(utilExec.NewExecutor(fakeExec)))
+			names, err := client.GetNamespaces(context.Background())
 
 			if tt.expectedErr && err == nil {
 				t.Error("expected error but got nil")
@@ -310,9 +325,8 @@ func TestGetSecrets(t *testing.T) {
 				},
 			}
 
-			client := NewClient()
-			utilExec.SetExec(fakeExec)
-			names, err := client.GetSecrets(tt.namespace)
+			client := NewClient(WithExecutor(utilExec.NewExecutor(fakeExec)))
+			names, err := client.GetSecrets(context.Background(), tt.namespace)
 
 			if tt.expectedErr && err == nil {
 				t.Error("expected error but got nil")
@@ -326,3 +340,31 @@ func TestGetSecrets(t *testing.T) {
 		})
 	}
 }
+
+func TestClientEnv(t *testing.T) {
+	fcmd := &testingexec.FakeCmd{
+		RunScript: []testingexec.FakeAction{
+			func() ([]byte, []byte, error) {
+				return []byte("default kube-system"), nil, nil
+			},
+		},
+	}
+	fakeExec := &testingexec.FakeExec{
+		CommandScript: []testingexec.FakeCommandAction{
+			func(cmd string, args ...string) exec.Cmd {
+				return fcmd
+			},
+		},
+	}
+
+	client := NewClient(WithExecutor(utilExec.NewExecutor(fakeExec, "KUBECONFIG=/tmp/staging.yaml")))
+	if _, err := client.GetNamespaces(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(fcmd.Env, "KUBECONFIG=/tmp/staging.yaml") {
+		t.Errorf("expected KUBECONFIG in the environment of kubectl, got %v", fcmd.Env)
+	}
+	if len(fcmd.Env) != len(os.Environ())+1 {
+		t.Errorf("expected the environment of optruck to be kept, got %d variables", len(fcmd.Env))
+	}
+}
@@ -1,5 +1,7 @@
 package op
 
+import "context"
+
 type Account struct {
 	URL         string `json:"url"`
 	Email       string `json:"email"`
@@ -7,10 +9,12 @@ type Account struct {
 	AccountUUID string `json:"account_uuid"`
 }
 
-func (c *ExecutableClient) ListAccounts() ([]Account, error) {
-	cmd := c.BuildCommand("account", "list")
+func (c *ExecutableClient) ListAccounts(ctx context.Context) ([]Account, error) {
 	var resp []Account
-	if err := cmd.RunWithJSON(nil, &resp); err != nil {
+	err := withRetry(ctx, func() error {
+		return c.BuildCommand(ctx, "account", "list").RunWithJSON(nil, &resp)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return resp, nil
@@ -1,6 +1,8 @@
 package op
 
 import (
+	"context"
+
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 
 	"testing"
@@ -92,10 +94,9 @@ func TestListAccounts(t *testing.T) {
 				},
 			}
 
-			client := NewExecutableClient()
-			utilExec.SetExec(fakeExec)
+			client := NewExecutableClient(WithExecutor(utilExec.NewExecutor(fakeExec)))
 
-			got, err := client.ListAccounts()
+			got, err := client.ListAccounts(context.Background())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListAccounts() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,79 @@
+package op
+
+import (
+	"context"
+	"sync"
+)
+
+type sessionCacheKey struct{}
+
+// WithSessionCache returns a context under which the vault and item listings
+// are kept for the rest of the run, as listing a large vault takes seconds
+// and a run, e.g. in interactive mode, can list the same vault more than
+// once. The item listings are dropped whenever an item is written.
+func WithSessionCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionCacheKey{}, &sessionCache{
+		vaults: map[string][]Vault{},
+		items:  map[string][]ItemResponse{},
+	})
+}
+
+type sessionCache struct {
+	mu sync.Mutex
+	// by account
+	vaults map[string][]Vault
+	// by account and vault
+	items map[string][]ItemResponse
+}
+
+// cacheFrom returns the cache of ctx, or nil, which lists every time.
+func cacheFrom(ctx context.Context) *sessionCache {
+	cache, _ := ctx.Value(sessionCacheKey{}).(*sessionCache)
+	return cache
+}
+
+func (s *sessionCache) listVaults(account string, list func() ([]Vault, error)) ([]Vault, error) {
+	if s == nil {
+		return list()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if vaults, ok := s.vaults[account]; ok {
+		return vaults, nil
+	}
+	vaults, err := list()
+	if err != nil {
+		return nil, err
+	}
+	s.vaults[account] = vaults
+	return vaults, nil
+}
+
+func (s *sessionCache) listItems(account, vault string, list func() ([]ItemResponse, error)) ([]ItemResponse, error) {
+	if s == nil {
+		return list()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := account + "\x00" + vault
+	if items, ok := s.items[key]; ok {
+		return items, nil
+	}
+	items, err := list()
+	if err != nil {
+		return nil, err
+	}
+	s.items[key] = items
+	return items, nil
+}
+
+// forgetItems drops the item listings of every vault, since a vault can be
+// given by its name or its ID.
+func (s *sessionCache) forgetItems() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.items)
+}
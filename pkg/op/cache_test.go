@@ -0,0 +1,51 @@
+package op
+
+import (
+	"context"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+)
+
+func TestSessionCache(t *testing.T) {
+	listVaults := fakeOpCall{
+		wantArgs: []string{"vault", "list", "--account", "test-account", "--format", "json"},
+		stdout:   `[{"id": "test-vault-id", "name": "test-vault-name"}]`,
+	}
+	listItems := fakeOpCall{
+		wantArgs: []string{"item", "list", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		stdout:   mockListStdoutSuccess,
+	}
+	editItem := fakeOpCall{
+		wantArgs: []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		stdout:   mockEditStdoutSuccess,
+	}
+
+	// the second listings are read from the cache, and the items are listed
+	// again once an item is written
+	fakeExec := newFakeOpExec(t, []fakeOpCall{listVaults, listItems, editItem, listItems})
+
+	ctx := WithSessionCache(context.Background())
+	client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+	for range 2 {
+		if _, err := client.ListVaults(ctx); err != nil {
+			t.Fatalf("ListVaults() error = %v", err)
+		}
+		if _, err := client.ListItems(ctx); err != nil {
+			t.Fatalf("ListItems() error = %v", err)
+		}
+	}
+	if _, err := client.EditItem(ctx, nil, map[string]string{"FOO": "foo2"}); err != nil {
+		t.Fatalf("EditItem() error = %v", err)
+	}
+	refs, err := client.ListItems(ctx)
+	if err != nil {
+		t.Fatalf("ListItems() error = %v", err)
+	}
+	if len(refs) == 0 {
+		t.Error("ListItems() returned no items")
+	}
+	if fakeExec.CommandCalls != 4 {
+		t.Errorf("ran op %d times, want 4", fakeExec.CommandCalls)
+	}
+}
@@ -1,10 +1,31 @@
 package op
 
+import (
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+)
+
 type ExecutableClient struct {
+	executor *utilExec.Executor
+}
+
+// Option configures a client.
+type Option func(*ExecutableClient)
+
+// WithExecutor runs the op commands of the client with e, e.g. a fake in
+// tests, or with OP_SESSION_<account> set for the client only. Without it, op
+// runs on the host.
+func WithExecutor(e *utilExec.Executor) Option {
+	return func(c *ExecutableClient) {
+		c.executor = e
+	}
 }
 
-func NewExecutableClient() *ExecutableClient {
-	return &ExecutableClient{}
+func NewExecutableClient(opts ...Option) *ExecutableClient {
+	c := &ExecutableClient{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type AccountClient struct {
@@ -12,9 +33,9 @@ type AccountClient struct {
 	Account string
 }
 
-func NewAccountClient(account string) *AccountClient {
+func NewAccountClient(account string, opts ...Option) *AccountClient {
 	return &AccountClient{
-		ExecutableClient: *NewExecutableClient(),
+		ExecutableClient: *NewExecutableClient(opts...),
 		Account:          account,
 	}
 }
@@ -24,9 +45,9 @@ type VaultClient struct {
 	Vault string
 }
 
-func NewVaultClient(account, vault string) *VaultClient {
+func NewVaultClient(account, vault string, opts ...Option) *VaultClient {
 	return &VaultClient{
-		AccountClient: *NewAccountClient(account),
+		AccountClient: *NewAccountClient(account, opts...),
 		Vault:         vault,
 	}
 }
@@ -36,9 +57,9 @@ type ItemClient struct {
 	ItemName string
 }
 
-func NewItemClient(account, vault, itemName string) *ItemClient {
+func NewItemClient(account, vault, itemName string, opts ...Option) *ItemClient {
 	return &ItemClient{
-		VaultClient: *NewVaultClient(account, vault),
+		VaultClient: *NewVaultClient(account, vault, opts...),
 		ItemName:    itemName,
 	}
 }
@@ -1,6 +1,8 @@
 package op
 
 import (
+	"context"
+
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 )
 
@@ -10,20 +12,35 @@ type CommandOptions struct {
 	Args       []string
 }
 
-func (c *ExecutableClient) BuildCommand(args ...string) utilExec.Command {
+func (c *ExecutableClient) BuildCommand(ctx context.Context, args ...string) utilExec.Command {
 	args = append(args, "--format", "json")
-	return utilExec.NewCommand("op", args...)
+	return c.newCommand(ctx, args...)
+}
+
+// accountArgs omits --account for a service account, which is bound to its
+// account.
+func (c *AccountClient) accountArgs() []string {
+	if c.Account == "" {
+		return nil
+	}
+	return []string{"--account", c.Account}
 }
 
-func (c *AccountClient) BuildCommand(args ...string) utilExec.Command {
-	args = append(args, "--account", c.Account)
+func (c *AccountClient) BuildCommand(ctx context.Context, args ...string) utilExec.Command {
+	args = append(args, c.accountArgs()...)
 	args = append(args, "--format", "json")
-	return utilExec.NewCommand("op", args...)
+	return c.newCommand(ctx, args...)
 }
 
-func (c *VaultClient) BuildCommand(args ...string) utilExec.Command {
-	args = append(args, "--account", c.Account)
+func (c *VaultClient) BuildCommand(ctx context.Context, args ...string) utilExec.Command {
+	args = append(args, c.accountArgs()...)
 	args = append(args, "--vault", c.Vault)
 	args = append(args, "--format", "json")
-	return utilExec.NewCommand("op", args...)
+	return c.newCommand(ctx, args...)
+}
+
+func (c *ExecutableClient) newCommand(ctx context.Context, args ...string) utilExec.Command {
+	cmd := c.executor.Command(ctx, "op", args...)
+	cmd.Classify = classifyError
+	return cmd
 }
@@ -1,27 +1,42 @@
 package op
 
 import (
+	"context"
+	"errors"
 	"sort"
 )
 
 type ItemCreateRequest struct {
 	Title    string
 	Category string
+	Sections []ItemSection `json:",omitempty"`
 	Fields   []ItemCreateRequestField
 }
 
 type ItemCreateRequestField struct {
 	ID      string
+	Section *ItemSection `json:",omitempty"`
 	Type    string
 	Purpose string
 	Label   string
 	Value   string
 }
 
-func (c *ItemClient) CreateItem(envPairs map[string]string) (*SecretReference, error) {
+// ItemSection is a section of an item. optruck puts the fields it writes in
+// FieldSection, as the 1Password Terraform provider only reads the fields
+// of a section.
+type ItemSection struct {
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+var FieldSection = ItemSection{ID: "optruck", Label: "optruck"}
+
+func (c *ItemClient) CreateItem(ctx context.Context, envPairs map[string]string) (*SecretReference, error) {
 	req := ItemCreateRequest{
 		Title:    c.ItemName,
 		Category: "LOGIN",
+		Sections: []ItemSection{FieldSection},
 		Fields:   make([]ItemCreateRequestField, 0, len(envPairs)),
 	}
 
@@ -31,20 +46,41 @@ func (c *ItemClient) CreateItem(envPairs map[string]string) (*SecretReference, e
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
+	ids := FieldIDs(keys)
 
 	// Add fields in sorted order
 	for _, k := range keys {
 		req.Fields = append(req.Fields, ItemCreateRequestField{
-			ID:    k,
-			Type:  "CONCEALED",
-			Label: k,
-			Value: envPairs[k],
+			ID:      ids[k],
+			Section: &FieldSection,
+			Type:    "CONCEALED",
+			Label:   k,
+			Value:   envPairs[k],
 		})
 	}
 
-	cmd := c.BuildCommand("item", "create")
+	defer cacheFrom(ctx).forgetItems()
 	var resp ItemResponse
-	if err := cmd.RunWithJSON(req, &resp); err != nil {
+	create := func() error {
+		return c.BuildCommand(ctx, "item", "create").RunWithJSON(req, &resp)
+	}
+	// the item is created when none has its name, so an item found by the
+	// name with the same fields is the one created by a failed attempt
+	created := func() (bool, error) {
+		item, err := c.GetItem(ctx)
+		if errors.Is(err, ErrItemNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if !hasFields(item, envPairs) {
+			return false, nil
+		}
+		resp = *item
+		return true, nil
+	}
+	if err := withWriteRetry(ctx, create, created); err != nil {
 		return nil, err
 	}
 	return c.BuildSecretReference(resp), nil
@@ -1,6 +1,8 @@
 package op
 
 import (
+	"context"
+
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 
 	"bytes"
@@ -101,7 +103,7 @@ func TestCreateItem(t *testing.T) {
 				FieldLabels: []string{"FOO", "BAR"},
 			},
 			wantArgs:  []string{"item", "create", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
-			wantStdin: `{"Title":"test-item","Category":"LOGIN","Fields":[{"ID":"BAR","Type":"CONCEALED","Purpose":"","Label":"BAR","Value":"baz"},{"ID":"FOO","Type":"CONCEALED","Purpose":"","Label":"FOO","Value":"bar"}]}`,
+			wantStdin: `{"Title":"test-item","Category":"LOGIN","Sections":[{"id":"optruck","label":"optruck"}],"Fields":[{"ID":"BAR","Section":{"id":"optruck","label":"optruck"},"Type":"CONCEALED","Purpose":"","Label":"BAR","Value":"baz"},{"ID":"FOO","Section":{"id":"optruck","label":"optruck"},"Type":"CONCEALED","Purpose":"","Label":"FOO","Value":"bar"}]}`,
 		},
 		{
 			name:     "verify exact json",
@@ -123,7 +125,7 @@ func TestCreateItem(t *testing.T) {
 				FieldLabels: []string{"FOO", "BAR"},
 			},
 			wantArgs:  []string{"item", "create", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
-			wantStdin: `{"Title":"test-item","Category":"LOGIN","Fields":[{"ID":"FOO","Type":"CONCEALED","Purpose":"","Label":"FOO","Value":"bar"}]}`,
+			wantStdin: `{"Title":"test-item","Category":"LOGIN","Sections":[{"id":"optruck","label":"optruck"}],"Fields":[{"ID":"FOO","Section":{"id":"optruck","label":"optruck"},"Type":"CONCEALED","Purpose":"","Label":"FOO","Value":"bar"}]}`,
 		},
 	}
 
@@ -184,10 +186,9 @@ func TestCreateItem(t *testing.T) {
 				},
 			}
 
-			client := NewItemClient(tt.account, tt.vault, tt.itemName)
-			utilExec.SetExec(fakeExec)
+			client := NewItemClient(tt.account, tt.vault, tt.itemName, WithExecutor(utilExec.NewExecutor(fakeExec)))
 
-			got, err := client.CreateItem(tt.envPairs)
+			got, err := client.CreateItem(context.Background(), tt.envPairs)
 			if err != tt.wantErr {
 				t.Errorf("CreateItem() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1,23 +1,35 @@
 package op
 
 import (
+	"context"
+	"slices"
 	"sort"
 )
 
 type ItemEditRequest struct {
-	Fields []ItemEditRequestField `json:"fields"`
+	Sections []ItemSection          `json:"sections"`
+	Fields   []ItemEditRequestField `json:"fields"`
 }
 
 type ItemEditRequestField struct {
-	ID    string `json:"id"`
-	Type  string `json:"type"`
-	Label string `json:"label"`
-	Value string `json:"value"`
+	ID      string       `json:"id"`
+	Section *ItemSection `json:"section"`
+	Type    string       `json:"type"`
+	Label   string       `json:"label"`
+	Value   string       `json:"value"`
 }
 
-func (c *ItemClient) EditItem(envPairs map[string]string) (*SecretReference, error) {
+// EditItem sets the fields of the item to envPairs. current is the item as
+// it is before the edit, or nil if it is not known: a label it already has
+// keeps the ID of its field, whether the field was made by an older optruck
+// or in the 1Password app, so that the field is updated instead of getting a
+// second one with the same label. Only new labels get the IDs of FieldIDs.
+// The fields outside of a section are moved to FieldSection, like the ones
+// of a new item.
+func (c *ItemClient) EditItem(ctx context.Context, current *ItemResponse, envPairs map[string]string) (*SecretReference, error) {
 	req := ItemEditRequest{
-		Fields: make([]ItemEditRequestField, 0, len(envPairs)),
+		Sections: editSections(current),
+		Fields:   make([]ItemEditRequestField, 0, len(envPairs)),
 	}
 
 	// Sort keys to ensure consistent order
@@ -26,22 +38,90 @@ func (c *ItemClient) EditItem(envPairs map[string]string) (*SecretReference, err
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
+	ids := editFieldIDs(current, keys)
 
 	// Add fields in sorted order
 	for _, k := range keys {
 		req.Fields = append(req.Fields, ItemEditRequestField{
-			ID:    k,
-			Type:  "CONCEALED",
-			Label: k,
-			Value: envPairs[k],
+			ID:      ids[k],
+			Section: fieldSection(current, k),
+			Type:    "CONCEALED",
+			Label:   k,
+			Value:   envPairs[k],
 		})
 	}
 
-	cmd := c.BuildCommand("item", "edit", c.ItemName)
+	defer cacheFrom(ctx).forgetItems()
 	var resp ItemResponse
-	if err := cmd.RunWithJSON(req, &resp); err != nil {
+	edit := func() error {
+		return c.BuildCommand(ctx, "item", "edit", c.ItemName).RunWithJSON(req, &resp)
+	}
+	edited := func() (bool, error) {
+		item, err := c.GetItem(ctx)
+		if err != nil {
+			return false, err
+		}
+		if !hasFields(item, envPairs) {
+			return false, nil
+		}
+		resp = *item
+		return true, nil
+	}
+	if err := withWriteRetry(ctx, edit, edited); err != nil {
 		return nil, err
 	}
 
 	return c.BuildSecretReference(resp), nil
 }
+
+// editSections returns the sections of current, and FieldSection.
+func editSections(current *ItemResponse) []ItemSection {
+	sections := []ItemSection{}
+	if current != nil {
+		sections = append(sections, current.Sections...)
+	}
+	if !slices.ContainsFunc(sections, func(s ItemSection) bool { return s.ID == FieldSection.ID }) {
+		sections = append(sections, FieldSection)
+	}
+	return sections
+}
+
+// fieldSection returns the section of the field labeled label in an edit of
+// current: the one it is in, or FieldSection.
+func fieldSection(current *ItemResponse, label string) *ItemSection {
+	if current != nil {
+		for _, field := range current.Fields {
+			if field.Label == label && field.Purpose == "" && field.Section != nil {
+				return field.Section
+			}
+		}
+	}
+	return &FieldSection
+}
+
+// editFieldIDs returns the IDs of the fields labeled labels in an edit of
+// current.
+func editFieldIDs(current *ItemResponse, labels []string) map[string]string {
+	existing := make(map[string]string)
+	if current != nil {
+		for _, field := range current.Fields {
+			if _, ok := existing[field.Label]; !ok && field.Purpose == "" {
+				existing[field.Label] = field.ID
+			}
+		}
+	}
+	// the labels of the item count for whether a new label is unique
+	all := slices.Clone(labels)
+	for label := range existing {
+		if !slices.Contains(labels, label) {
+			all = append(all, label)
+		}
+	}
+	ids := FieldIDs(all)
+	for _, label := range labels {
+		if id, ok := existing[label]; ok {
+			ids[label] = id
+		}
+	}
+	return ids
+}
@@ -1,6 +1,8 @@
 package op
 
 import (
+	"context"
+
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 
 	"bytes"
@@ -118,6 +120,7 @@ func TestEditItem(t *testing.T) {
 		itemName       string
 		account        string
 		vault          string
+		current        *ItemResponse
 		envPairs       map[string]string
 		mockStdout     string
 		mockStderr     string
@@ -148,7 +151,7 @@ func TestEditItem(t *testing.T) {
 				FieldLabels: []string{"FOO", "BAR"},
 			},
 			wantArgs:  []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
-			wantStdin: `{"fields":[{"id":"FOO","type":"CONCEALED","label":"FOO","value":"bar"},{"id":"BAR","type":"CONCEALED","label":"BAR","value":"baz"}]}`,
+			wantStdin: `{"sections":[{"id":"optruck","label":"optruck"}],"fields":[{"id":"FOO","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"FOO","value":"bar"},{"id":"BAR","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"BAR","value":"baz"}]}`,
 		},
 		{
 			name:     "verify exact json",
@@ -170,7 +173,7 @@ func TestEditItem(t *testing.T) {
 				FieldLabels: []string{"FOO", "BAR"},
 			},
 			wantArgs:  []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
-			wantStdin: `{"fields":[{"id":"FOO","type":"CONCEALED","label":"FOO","value":"bar"}]}`,
+			wantStdin: `{"sections":[{"id":"optruck","label":"optruck"}],"fields":[{"id":"FOO","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"FOO","value":"bar"}]}`,
 		},
 		{
 			name:     "modify existing fields",
@@ -193,7 +196,7 @@ func TestEditItem(t *testing.T) {
 				FieldLabels: []string{"FOO", "BAR"},
 			},
 			wantArgs:  []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
-			wantStdin: `{"fields":[{"id":"FOO","type":"CONCEALED","label":"FOO","value":"modified_bar"},{"id":"BAR","type":"CONCEALED","label":"BAR","value":"modified_baz"}]}`,
+			wantStdin: `{"sections":[{"id":"optruck","label":"optruck"}],"fields":[{"id":"FOO","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"FOO","value":"modified_bar"},{"id":"BAR","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"BAR","value":"modified_baz"}]}`,
 		},
 		{
 			name:     "add new field",
@@ -217,7 +220,7 @@ func TestEditItem(t *testing.T) {
 				FieldLabels: []string{"FOO", "BAR", "BAZ"},
 			},
 			wantArgs:  []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
-			wantStdin: `{"fields":[{"id":"FOO","type":"CONCEALED","label":"FOO","value":"bar"},{"id":"BAR","type":"CONCEALED","label":"BAR","value":"baz"},{"id":"BAZ","type":"CONCEALED","label":"BAZ","value":"qux"}]}`,
+			wantStdin: `{"sections":[{"id":"optruck","label":"optruck"}],"fields":[{"id":"FOO","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"FOO","value":"bar"},{"id":"BAR","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"BAR","value":"baz"},{"id":"BAZ","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"BAZ","value":"qux"}]}`,
 		},
 		{
 			name:     "remove field",
@@ -239,7 +242,36 @@ func TestEditItem(t *testing.T) {
 				FieldLabels: []string{"FOO", "BAR"},
 			},
 			wantArgs:  []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
-			wantStdin: `{"fields":[{"id":"FOO","type":"CONCEALED","label":"FOO","value":"bar"}]}`,
+			wantStdin: `{"sections":[{"id":"optruck","label":"optruck"}],"fields":[{"id":"FOO","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"FOO","value":"bar"}]}`,
+		},
+		{
+			name:     "keep the ids and sections of existing fields",
+			itemName: "test-item",
+			account:  "test-account",
+			vault:    "test-vault-name",
+			current: &ItemResponse{Sections: []ItemSection{{ID: "app", Label: "App"}}, Fields: []ItemResponseField{
+				{ID: "notesPlain", Purpose: "NOTES", Label: "notesPlain"},
+				{ID: "tls.crt", Type: "CONCEALED", Label: "tls.crt", Value: "old-crt"},
+				{ID: "k7x2mqd4", Section: &ItemSection{ID: "app", Label: "App"}, Type: "CONCEALED", Label: "API_KEY", Value: "old-key"},
+			}},
+			envPairs: map[string]string{
+				"tls.crt": "new-crt",
+				"API_KEY": "new-key",
+				"tls.key": "new-key",
+			},
+			mockStdout:     mockEditStdoutSuccess,
+			mockExitStatus: 0,
+			wantErr:        nil,
+			wantRef: &SecretReference{
+				Account:     "test-account",
+				VaultName:   "test-vault-name",
+				VaultID:     "test-vault-id",
+				ItemName:    "test-item",
+				ItemID:      "test-id",
+				FieldLabels: []string{"FOO", "BAR"},
+			},
+			wantArgs:  []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			wantStdin: `{"sections":[{"id":"app","label":"App"},{"id":"optruck","label":"optruck"}],"fields":[{"id":"k7x2mqd4","section":{"id":"app","label":"App"},"type":"CONCEALED","label":"API_KEY","value":"new-key"},{"id":"tls.crt","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"tls.crt","value":"new-crt"},{"id":"` + hashedFieldID("tls.key") + `","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"tls.key","value":"new-key"}]}`,
 		},
 	}
 
@@ -253,11 +285,13 @@ func TestEditItem(t *testing.T) {
 						if tt.wantStdin != "" {
 							gotJSON := fcmd.Stdin.(*bytes.Buffer).Bytes()
 							var got, want struct {
-								Fields []struct {
-									ID    string `json:"id"`
-									Type  string `json:"type"`
-									Label string `json:"label"`
-									Value string `json:"value"`
+								Sections []ItemSection `json:"sections"`
+								Fields   []struct {
+									ID      string       `json:"id"`
+									Section *ItemSection `json:"section"`
+									Type    string       `json:"type"`
+									Label   string       `json:"label"`
+									Value   string       `json:"value"`
 								} `json:"fields"`
 							}
 							if err := json.Unmarshal(gotJSON, &got); err != nil {
@@ -316,10 +350,9 @@ func TestEditItem(t *testing.T) {
 				},
 			}
 
-			client := NewItemClient(tt.account, tt.vault, tt.itemName)
-			utilExec.SetExec(fakeExec)
+			client := NewItemClient(tt.account, tt.vault, tt.itemName, WithExecutor(utilExec.NewExecutor(fakeExec)))
 
-			got, err := client.EditItem(tt.envPairs)
+			got, err := client.EditItem(context.Background(), tt.current, tt.envPairs)
 			if err != tt.wantErr {
 				t.Errorf("EditItem() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,54 @@
+package op
+
+import (
+	"errors"
+	"strings"
+)
+
+// The reasons an op command fails that the user can act on. The errors of
+// the clients wrap them when op tells the reason in its stderr.
+var (
+	ErrNotSignedIn      = errors.New("not signed in to 1Password")
+	ErrAccountNotFound  = errors.New("1Password account not found")
+	ErrVaultNotFound    = errors.New("1Password vault not found")
+	ErrItemNotFound     = errors.New("1Password item not found")
+	ErrPermissionDenied = errors.New("permission denied by 1Password")
+	ErrRateLimited      = errors.New("rate limited by 1Password")
+	ErrNetwork          = errors.New("failed to reach 1Password")
+)
+
+// stderrPatterns are matched in order against the lower-cased stderr of op.
+// The item messages name the vault of the item, so they come first.
+var stderrPatterns = []struct {
+	substrings []string
+	err        error
+}{
+	{[]string{"not currently signed in", "not signed in", "session expired", "no accounts configured"}, ErrNotSignedIn},
+	{[]string{"more than one item matches"}, ErrMoreThanOneItemFound},
+	{[]string{"isn't an item", "item not found", "no item found"}, ErrItemNotFound},
+	{[]string{"isn't a vault", "vault not found", "no vault found"}, ErrVaultNotFound},
+	{[]string{"no account found", "account not found", "isn't an account"}, ErrAccountNotFound},
+	{[]string{"permission denied", "do not have permission", "don't have permission", "forbidden (403)"}, ErrPermissionDenied},
+	{[]string{"too many requests", "rate limit", "(429)"}, ErrRateLimited},
+	{[]string{"connection reset", "connection refused", "i/o timeout", "no such host", "tls handshake timeout", "unexpected eof", "bad gateway", "service unavailable", "gateway timeout", "(502)", "(503)", "(504)"}, ErrNetwork},
+}
+
+// isTransient reports whether err may not happen again if the same op
+// command is run a little later.
+func isTransient(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrNetwork)
+}
+
+// classifyError returns the reason of a failed op command from its stderr,
+// or nil if it is not known.
+func classifyError(stderr string) error {
+	stderr = strings.ToLower(stderr)
+	for _, p := range stderrPatterns {
+		for _, s := range p.substrings {
+			if strings.Contains(stderr, s) {
+				return p.err
+			}
+		}
+	}
+	return nil
+}
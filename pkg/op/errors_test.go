@@ -0,0 +1,82 @@
+package op
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{
+			name:   "not signed in",
+			stderr: "[ERROR] 2026/10/19 10:00:00 You are not currently signed in. Please run `op signin --help` for instructions",
+			want:   ErrNotSignedIn,
+		},
+		{
+			name:   "account not found",
+			stderr: `[ERROR] 2026/10/19 10:00:00 no account found for filter "other.1password.com"`,
+			want:   ErrAccountNotFound,
+		},
+		{
+			name:   "vault not found",
+			stderr: `[ERROR] 2026/10/19 10:00:00 "Staging" isn't a vault in this account. Specify the vault with its ID or name.`,
+			want:   ErrVaultNotFound,
+		},
+		{
+			name:   "item not found in a vault",
+			stderr: `[ERROR] 2026/10/19 10:00:00 "my-app" isn't an item in the "Development" vault. Specify the item with its UUID, name, or domain.`,
+			want:   ErrItemNotFound,
+		},
+		{
+			name:   "permission denied",
+			stderr: "[ERROR] 2026/10/19 10:00:00 You do not have permission to perform this action",
+			want:   ErrPermissionDenied,
+		},
+		{
+			name:   "rate limited",
+			stderr: "[ERROR] 2026/10/19 10:00:00 Too many requests (429). Please try again later",
+			want:   ErrRateLimited,
+		},
+		{
+			name:   "unknown",
+			stderr: "[ERROR] 2026/10/19 10:00:00 unexpected response",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.stderr); got != tt.want {
+				t.Errorf("classifyError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemClientErrorKind(t *testing.T) {
+	stderr := `[ERROR] 2026/10/19 10:00:00 "my-app" isn't an item in the "Development" vault.`
+	fakeExec := newFakeOpExec(t, []fakeOpCall{
+		{
+			wantArgs: []string{"item", "get", "my-app", "--reveal", "--account", "my.1password.com", "--vault", "Development", "--format", "json"},
+			exitCode: 1,
+			stderr:   stderr,
+		},
+	})
+
+	client := NewItemClient("my.1password.com", "Development", "my-app", WithExecutor(utilExec.NewExecutor(fakeExec)))
+	_, err := client.GetItem(context.Background())
+	if !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("GetItem() error = %v, want %v", err, ErrItemNotFound)
+	}
+	var cmdErr *utilExec.CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Stderr != stderr {
+		t.Errorf("GetItem() error = %v, want a CommandError with the stderr of op", err)
+	}
+}
@@ -0,0 +1,14 @@
+package op
+
+import "context"
+
+func (c *ItemClient) GetItem(ctx context.Context) (*ItemResponse, error) {
+	var resp ItemResponse
+	err := withRetry(ctx, func() error {
+		return c.BuildCommand(ctx, "item", "get", c.ItemName, "--reveal").RunWithJSON(nil, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,230 @@
+package op
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	utilLogger "github.com/yammerjp/optruck/internal/util/logger"
+)
+
+// 1Password CLI does not expose the version history of an item, so optruck
+// keeps its own: before an item is overwritten, the current fields are copied
+// into an archived item tagged with historyTagPrefix + the item ID.
+const historyTagPrefix = "optruck-history/"
+
+var ErrVersionNotFound = errors.New("version not found in the item history, run `optruck history <item>` to see available versions")
+var ErrAlreadyAtVersion = errors.New("item is already at the requested version")
+
+type ItemVersion struct {
+	Version   int
+	UpdatedAt string
+	Current   bool
+	Fields    map[string]string
+}
+
+type snapshotMetadata struct {
+	ItemID    string `json:"item_id"`
+	Version   int    `json:"version"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type snapshotCreateRequest struct {
+	Title    string                   `json:"title"`
+	Category string                   `json:"category"`
+	Tags     []string                 `json:"tags"`
+	Fields   []ItemCreateRequestField `json:"fields"`
+}
+
+func historyTag(itemID string) string {
+	return historyTagPrefix + itemID
+}
+
+// SaveSnapshot stores the given state of the item as an archived history
+// snapshot in the same vault. A snapshot that cannot be archived is deleted,
+// so that no live item is left with a copy of the secrets.
+func (c *ItemClient) SaveSnapshot(ctx context.Context, item *ItemResponse) error {
+	meta, err := json.Marshal(snapshotMetadata{
+		ItemID:    item.ID,
+		Version:   item.Version,
+		UpdatedAt: item.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req := snapshotCreateRequest{
+		Title:    fmt.Sprintf("%s (optruck history v%d)", item.Title, item.Version),
+		Category: "SECURE_NOTE",
+		Tags:     []string{historyTag(item.ID)},
+		Fields: []ItemCreateRequestField{
+			{
+				ID:      "notesPlain",
+				Type:    "STRING",
+				Purpose: "NOTES",
+				Label:   "notesPlain",
+				Value:   string(meta),
+			},
+		},
+	}
+	for _, field := range item.Fields {
+		if field.Purpose != "" {
+			continue
+		}
+		req.Fields = append(req.Fields, ItemCreateRequestField{
+			ID:    field.ID,
+			Type:  field.Type,
+			Label: field.Label,
+			Value: field.Value,
+		})
+	}
+
+	defer cacheFrom(ctx).forgetItems()
+	cmd := c.BuildCommand(ctx, "item", "create")
+	var resp ItemResponse
+	if err := cmd.RunWithJSON(req, &resp); err != nil {
+		return fmt.Errorf("failed to create history snapshot: %w", err)
+	}
+
+	archiveCmd := c.BuildCommand(ctx, "item", "delete", resp.ID, "--archive")
+	if err := archiveCmd.Run(nil, nil); err != nil {
+		deleteCmd := c.BuildCommand(ctx, "item", "delete", resp.ID)
+		if deleteErr := deleteCmd.Run(nil, nil); deleteErr != nil {
+			return fmt.Errorf("failed to archive history snapshot %s, and failed to delete it, please delete it in 1Password: %w", resp.ID, errors.Join(err, deleteErr))
+		}
+		return fmt.Errorf("failed to archive history snapshot %s, so it was deleted: %w", resp.ID, err)
+	}
+	utilLogger.FromContext(ctx).Debug("saved history snapshot", "item", item.ID, "version", item.Version, "snapshot", resp.ID)
+	return nil
+}
+
+// ListVersions returns the known versions of the item ordered from the oldest
+// to the current one.
+func (c *ItemClient) ListVersions(ctx context.Context) ([]ItemVersion, error) {
+	current, err := c.GetItem(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	return c.listVersions(ctx, current)
+}
+
+func (c *ItemClient) listVersions(ctx context.Context, current *ItemResponse) ([]ItemVersion, error) {
+	var snapshots []ItemResponse
+	err := withRetry(ctx, func() error {
+		return c.BuildCommand(ctx, "item", "list", "--tags", historyTag(current.ID), "--include-archive").RunWithJSON(nil, &snapshots)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history snapshots: %w", err)
+	}
+
+	seen := map[int]bool{current.Version: true}
+	versions := []ItemVersion{}
+	for _, snapshot := range snapshots {
+		var resp ItemResponse
+		err := withRetry(ctx, func() error {
+			return c.BuildCommand(ctx, "item", "get", snapshot.ID, "--include-archive", "--reveal").RunWithJSON(nil, &resp)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get history snapshot %s: %w", snapshot.ID, err)
+		}
+		version, ok := snapshotToVersion(resp, current.ID)
+		if !ok {
+			utilLogger.FromContext(ctx).Warn("skipping unrecognized history snapshot", "snapshot", snapshot.ID)
+			continue
+		}
+		// a failed overwrite can leave several snapshots of the same version
+		if seen[version.Version] {
+			continue
+		}
+		seen[version.Version] = true
+		versions = append(versions, version)
+	}
+
+	versions = append(versions, ItemVersion{
+		Version:   current.Version,
+		UpdatedAt: current.UpdatedAt,
+		Current:   true,
+		Fields:    userFields(*current),
+	})
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions, nil
+}
+
+// Rollback restores the field values of the given version through EditItem.
+// The current state is saved as a snapshot first, so a rollback can be
+// rolled back as well. Fields added after the given version are kept.
+func (c *ItemClient) Rollback(ctx context.Context, version int) (*SecretReference, error) {
+	pending, err := c.PrepareRollback(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	upload, err := c.CommitUpload(ctx, pending)
+	if err != nil {
+		return nil, err
+	}
+	return upload.SecretReference, nil
+}
+
+// PrepareRollback looks up the given version as Rollback would, and returns
+// the overwrite restoring it for CommitUpload, so that the templates can be
+// checked before the item changes.
+func (c *ItemClient) PrepareRollback(ctx context.Context, version int) (*PendingUpload, error) {
+	current, err := c.GetItem(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	if current.Version == version {
+		return nil, ErrAlreadyAtVersion
+	}
+	versions, err := c.listVersions(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *ItemVersion
+	for i := range versions {
+		if versions[i].Version == version {
+			target = &versions[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	return &PendingUpload{SecretReference: c.editedReference(current, target.Fields), envPairs: target.Fields, current: current}, nil
+}
+
+func snapshotToVersion(snapshot ItemResponse, itemID string) (ItemVersion, bool) {
+	var meta *snapshotMetadata
+	for _, field := range snapshot.Fields {
+		if field.Purpose != "NOTES" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(field.Value), &meta); err != nil {
+			return ItemVersion{}, false
+		}
+	}
+	if meta == nil || meta.ItemID != itemID {
+		return ItemVersion{}, false
+	}
+	return ItemVersion{
+		Version:   meta.Version,
+		UpdatedAt: meta.UpdatedAt,
+		Fields:    userFields(snapshot),
+	}, true
+}
+
+func userFields(item ItemResponse) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range item.Fields {
+		if field.Purpose == "" {
+			fields[field.Label] = field.Value
+		}
+	}
+	return fields
+}
@@ -0,0 +1,297 @@
+package op
+
+import (
+	"context"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/exec"
+	testingexec "k8s.io/utils/exec/testing"
+)
+
+var mockGetCurrentStdout = `{
+  "id": "test-id",
+  "title": "test-item",
+  "version": 3,
+  "vault": {
+    "id": "test-vault-id",
+    "name": "test-vault-name"
+  },
+  "category": "LOGIN",
+  "updated_at": "2025-01-27T10:00:00+09:00",
+  "fields": [
+    {"id": "password", "type": "CONCEALED", "purpose": "PASSWORD", "label": "password"},
+    {"id": "FOO", "type": "CONCEALED", "label": "FOO", "value": "foo3"},
+    {"id": "BAR", "type": "CONCEALED", "label": "BAR", "value": "bar3"}
+  ]
+}`
+
+var mockHistoryListStdout = `[
+  {"id": "snapshot-2", "title": "test-item (optruck history v2)", "version": 1},
+  {"id": "snapshot-1", "title": "test-item (optruck history v1)", "version": 1}
+]`
+
+var mockSnapshot1Stdout = `{
+  "id": "snapshot-1",
+  "title": "test-item (optruck history v1)",
+  "version": 1,
+  "fields": [
+    {"id": "notesPlain", "type": "STRING", "purpose": "NOTES", "label": "notesPlain", "value": "{\"item_id\":\"test-id\",\"version\":1,\"updated_at\":\"2025-01-25T10:00:00+09:00\"}"},
+    {"id": "FOO", "type": "CONCEALED", "label": "FOO", "value": "foo1"}
+  ]
+}`
+
+var mockSnapshot2Stdout = `{
+  "id": "snapshot-2",
+  "title": "test-item (optruck history v2)",
+  "version": 1,
+  "fields": [
+    {"id": "notesPlain", "type": "STRING", "purpose": "NOTES", "label": "notesPlain", "value": "{\"item_id\":\"test-id\",\"version\":2,\"updated_at\":\"2025-01-26T10:00:00+09:00\"}"},
+    {"id": "FOO", "type": "CONCEALED", "label": "FOO", "value": "foo2"},
+    {"id": "BAR", "type": "CONCEALED", "label": "BAR", "value": "bar2"}
+  ]
+}`
+
+type fakeOpCall struct {
+	wantArgs []string
+	stdout   string
+	exitCode int
+	stderr   string
+	stdin    *[]byte
+}
+
+func newFakeOpExec(t *testing.T, calls []fakeOpCall) *testingexec.FakeExec {
+	t.Helper()
+	fakeExec := &testingexec.FakeExec{}
+	for _, call := range calls {
+		call := call
+		fcmd := &testingexec.FakeCmd{}
+		fcmd.RunScript = []testingexec.FakeAction{
+			func() ([]byte, []byte, error) {
+				if call.stdin != nil && fcmd.Stdin != nil {
+					*call.stdin = fcmd.Stdin.(*bytes.Buffer).Bytes()
+				}
+				if call.exitCode != 0 {
+					stderr := call.stderr
+					if stderr == "" {
+						stderr = "[ERROR] failed"
+					}
+					return nil, []byte(stderr), &testingexec.FakeExitError{Status: call.exitCode}
+				}
+				if call.stdout == "" {
+					return nil, nil, nil
+				}
+				return []byte(call.stdout), nil, nil
+			},
+		}
+		fakeExec.CommandScript = append(fakeExec.CommandScript, func(cmd string, args ...string) exec.Cmd {
+			if cmd != "op" {
+				t.Errorf("expected command 'op', got %s", cmd)
+			}
+			if !reflect.DeepEqual(args, call.wantArgs) {
+				t.Errorf("args = %v, want %v", args, call.wantArgs)
+			}
+			return fcmd
+		})
+	}
+	return fakeExec
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	var item ItemResponse
+	if err := json.Unmarshal([]byte(mockGetCurrentStdout), &item); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdin []byte
+	fakeExec := newFakeOpExec(t, []fakeOpCall{
+		{
+			wantArgs: []string{"item", "create", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			stdout:   `{"id": "snapshot-3", "title": "test-item (optruck history v3)"}`,
+			stdin:    &stdin,
+		},
+		{
+			wantArgs: []string{"item", "delete", "snapshot-3", "--archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		},
+	})
+
+	client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+	if err := client.SaveSnapshot(context.Background(), &item); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	want := `{"title":"test-item (optruck history v3)","category":"SECURE_NOTE","tags":["optruck-history/test-id"],"fields":[` +
+		`{"ID":"notesPlain","Type":"STRING","Purpose":"NOTES","Label":"notesPlain","Value":"{\"item_id\":\"test-id\",\"version\":3,\"updated_at\":\"2025-01-27T10:00:00+09:00\"}"},` +
+		`{"ID":"FOO","Type":"CONCEALED","Purpose":"","Label":"FOO","Value":"foo3"},` +
+		`{"ID":"BAR","Type":"CONCEALED","Purpose":"","Label":"BAR","Value":"bar3"}]}`
+	if string(stdin) != want {
+		t.Errorf("stdin JSON = %s, want %s", stdin, want)
+	}
+	if fakeExec.CommandCalls != 2 {
+		t.Errorf("expected 2 commands, got %d", fakeExec.CommandCalls)
+	}
+}
+
+func TestSaveSnapshotArchiveFailure(t *testing.T) {
+	var item ItemResponse
+	if err := json.Unmarshal([]byte(mockGetCurrentStdout), &item); err != nil {
+		t.Fatal(err)
+	}
+	create := fakeOpCall{
+		wantArgs: []string{"item", "create", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		stdout:   `{"id": "snapshot-3", "title": "test-item (optruck history v3)"}`,
+	}
+	archive := fakeOpCall{
+		wantArgs: []string{"item", "delete", "snapshot-3", "--archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		exitCode: 1,
+	}
+	deleteArgs := []string{"item", "delete", "snapshot-3", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"}
+
+	tests := []struct {
+		name           string
+		deleteExitCode int
+		wantErr        string
+	}{
+		{
+			name:    "deletes the snapshot",
+			wantErr: "failed to archive history snapshot snapshot-3, so it was deleted",
+		},
+		{
+			name:           "tells to delete the snapshot",
+			deleteExitCode: 1,
+			wantErr:        "failed to archive history snapshot snapshot-3, and failed to delete it, please delete it in 1Password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeExec := newFakeOpExec(t, []fakeOpCall{create, archive, {wantArgs: deleteArgs, exitCode: tt.deleteExitCode}})
+
+			client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+			err := client.SaveSnapshot(context.Background(), &item)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SaveSnapshot() error = %v, want %q", err, tt.wantErr)
+			}
+			if fakeExec.CommandCalls != 3 {
+				t.Errorf("expected 3 commands, got %d", fakeExec.CommandCalls)
+			}
+		})
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	fakeExec := newFakeOpExec(t, []fakeOpCall{
+		{
+			wantArgs: []string{"item", "get", "test-item", "--reveal", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			stdout:   mockGetCurrentStdout,
+		},
+		{
+			wantArgs: []string{"item", "list", "--tags", "optruck-history/test-id", "--include-archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			stdout:   mockHistoryListStdout,
+		},
+		{
+			wantArgs: []string{"item", "get", "snapshot-2", "--include-archive", "--reveal", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			stdout:   mockSnapshot2Stdout,
+		},
+		{
+			wantArgs: []string{"item", "get", "snapshot-1", "--include-archive", "--reveal", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			stdout:   mockSnapshot1Stdout,
+		},
+	})
+
+	client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+	got, err := client.ListVersions(context.Background())
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+
+	want := []ItemVersion{
+		{Version: 1, UpdatedAt: "2025-01-25T10:00:00+09:00", Fields: map[string]string{"FOO": "foo1"}},
+		{Version: 2, UpdatedAt: "2025-01-26T10:00:00+09:00", Fields: map[string]string{"FOO": "foo2", "BAR": "bar2"}},
+		{Version: 3, UpdatedAt: "2025-01-27T10:00:00+09:00", Current: true, Fields: map[string]string{"FOO": "foo3", "BAR": "bar3"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListVersions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	getCurrent := fakeOpCall{
+		wantArgs: []string{"item", "get", "test-item", "--reveal", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		stdout:   mockGetCurrentStdout,
+	}
+	listHistory := fakeOpCall{
+		wantArgs: []string{"item", "list", "--tags", "optruck-history/test-id", "--include-archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		stdout:   mockHistoryListStdout,
+	}
+	getSnapshot2 := fakeOpCall{
+		wantArgs: []string{"item", "get", "snapshot-2", "--include-archive", "--reveal", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		stdout:   mockSnapshot2Stdout,
+	}
+	getSnapshot1 := fakeOpCall{
+		wantArgs: []string{"item", "get", "snapshot-1", "--include-archive", "--reveal", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+		stdout:   mockSnapshot1Stdout,
+	}
+
+	t.Run("restore previous version", func(t *testing.T) {
+		var editStdin []byte
+		fakeExec := newFakeOpExec(t, []fakeOpCall{
+			getCurrent,
+			listHistory,
+			getSnapshot2,
+			getSnapshot1,
+			{
+				wantArgs: []string{"item", "create", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+				stdout:   `{"id": "snapshot-3"}`,
+			},
+			{
+				wantArgs: []string{"item", "delete", "snapshot-3", "--archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			},
+			{
+				wantArgs: []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+				stdout:   mockEditStdoutSuccess,
+				stdin:    &editStdin,
+			},
+		})
+
+		client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+		ref, err := client.Rollback(context.Background(), 2)
+		if err != nil {
+			t.Fatalf("Rollback() error = %v", err)
+		}
+		if ref.ItemID != "test-id" {
+			t.Errorf("Rollback() ItemID = %v, want test-id", ref.ItemID)
+		}
+		wantStdin := `{"sections":[{"id":"optruck","label":"optruck"}],"fields":[{"id":"BAR","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"BAR","value":"bar2"},{"id":"FOO","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"FOO","value":"foo2"}]}`
+		if string(editStdin) != wantStdin {
+			t.Errorf("edit stdin JSON = %s, want %s", editStdin, wantStdin)
+		}
+		if fakeExec.CommandCalls != 7 {
+			t.Errorf("expected 7 commands, got %d", fakeExec.CommandCalls)
+		}
+	})
+
+	t.Run("version not found", func(t *testing.T) {
+		fakeExec := newFakeOpExec(t, []fakeOpCall{getCurrent, listHistory, getSnapshot2, getSnapshot1})
+
+		client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+		if _, err := client.Rollback(context.Background(), 5); err != ErrVersionNotFound {
+			t.Errorf("Rollback() error = %v, want %v", err, ErrVersionNotFound)
+		}
+	})
+
+	t.Run("already at version", func(t *testing.T) {
+		fakeExec := newFakeOpExec(t, []fakeOpCall{getCurrent})
+
+		client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+		if _, err := client.Rollback(context.Background(), 3); err != ErrAlreadyAtVersion {
+			t.Errorf("Rollback() error = %v, want %v", err, ErrAlreadyAtVersion)
+		}
+	})
+}
@@ -1,9 +1,16 @@
 package op
 
-func (c *VaultClient) ListItems() ([]SecretReference, error) {
-	cmd := c.BuildCommand("item", "list")
-	var resp []ItemResponse
-	if err := cmd.RunWithJSON(nil, &resp); err != nil {
+import "context"
+
+func (c *VaultClient) ListItems(ctx context.Context) ([]SecretReference, error) {
+	resp, err := cacheFrom(ctx).listItems(c.Account, c.Vault, func() ([]ItemResponse, error) {
+		var resp []ItemResponse
+		err := withRetry(ctx, func() error {
+			return c.BuildCommand(ctx, "item", "list").RunWithJSON(nil, &resp)
+		})
+		return resp, err
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -13,18 +20,3 @@ func (c *VaultClient) ListItems() ([]SecretReference, error) {
 	}
 	return refs, nil
 }
-
-func (c *VaultClient) FilterItems(itemSpecifier string) ([]SecretReference, error) {
-	refs, err := c.ListItems()
-	if err != nil {
-		return nil, err
-	}
-
-	filtered := make([]SecretReference, 0)
-	for _, ref := range refs {
-		if itemSpecifier == ref.ItemName || itemSpecifier == ref.ItemID {
-			filtered = append(filtered, ref)
-		}
-	}
-	return filtered, nil
-}
@@ -1,6 +1,8 @@
 package op
 
 import (
+	"context"
+
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
 
 	"testing"
@@ -102,6 +104,30 @@ func TestListItems(t *testing.T) {
 			},
 			wantArgs: []string{"item", "list", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
 		},
+		{
+			name:           "service account",
+			vault:          "test-vault-name",
+			mockStdout:     mockListStdoutSuccess,
+			mockExitStatus: 0,
+			wantErr:        nil,
+			wantRefs: []SecretReference{
+				{
+					VaultName:   "test-vault-name",
+					VaultID:     "test-vault-id",
+					ItemName:    "test-item-1",
+					ItemID:      "test-id-1",
+					FieldLabels: []string{"FOO", "BAR"},
+				},
+				{
+					VaultName:   "test-vault-name",
+					VaultID:     "test-vault-id",
+					ItemName:    "test-item-2",
+					ItemID:      "test-id-2",
+					FieldLabels: []string{"BAZ"},
+				},
+			},
+			wantArgs: []string{"item", "list", "--vault", "test-vault-name", "--format", "json"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -137,10 +163,9 @@ func TestListItems(t *testing.T) {
 				},
 			}
 
-			client := NewVaultClient(tt.account, tt.vault)
-			utilExec.SetExec(fakeExec)
+			client := NewVaultClient(tt.account, tt.vault, WithExecutor(utilExec.NewExecutor(fakeExec)))
 
-			got, err := client.ListItems()
+			got, err := client.ListItems(context.Background())
 			if err != tt.wantErr {
 				t.Errorf("ListItems() error = %v, wantErr %v", err, tt.wantErr)
 				return
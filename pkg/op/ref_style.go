@@ -0,0 +1,150 @@
+package op
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type RefStyle string
+
+const (
+	// RefStyleID references the vault and item by ID, which is stable across renames.
+	RefStyleID RefStyle = "id"
+	// RefStyleName references the vault and item by name, which is readable in code review.
+	RefStyleName RefStyle = "name"
+)
+
+var vaultVarRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var ErrNameNotReferenceSafe = errors.New("name cannot be used in a secret reference, it may only contain letters, digits, '-' and '_'. Rename it or use --ref-style id")
+var ErrNameNotUnique = errors.New("name is not unique, references by name would be ambiguous. Rename it or use --ref-style id")
+
+// RefOptions controls how the references in a template point to the item.
+type RefOptions struct {
+	RefStyle RefStyle
+	// VaultVar replaces the vault with `${VaultVar}`, so that one template
+	// resolves against identical items in several vaults. The item is then
+	// always referenced by name, since the IDs differ between vaults.
+	VaultVar string
+}
+
+func (o RefOptions) GetRefOptions() RefOptions {
+	return o
+}
+
+// NeedsNameReference reports whether the references use the vault or item name.
+func (o RefOptions) NeedsNameReference() bool {
+	return o.RefStyle == RefStyleName || o.VaultVar != ""
+}
+
+func ParseRefOptions(style, vaultVar string) (RefOptions, error) {
+	refStyle, err := ParseRefStyle(style)
+	if err != nil {
+		return RefOptions{}, err
+	}
+	if vaultVar != "" && !vaultVarRegex.MatchString(vaultVar) {
+		return RefOptions{}, fmt.Errorf("invalid vault variable name: %s, it must consist of letters, digits and '_', and must not start with a digit", vaultVar)
+	}
+	return RefOptions{RefStyle: refStyle, VaultVar: vaultVar}, nil
+}
+
+func ParseRefStyle(s string) (RefStyle, error) {
+	switch RefStyle(s) {
+	case "", RefStyleID:
+		return RefStyleID, nil
+	case RefStyleName:
+		return RefStyleName, nil
+	default:
+		return "", fmt.Errorf("invalid reference style: %s, use id or name", s)
+	}
+}
+
+// GetItemRef returns the `op://<vault>/<item>` prefix of the references.
+func (sr *SecretReference) GetItemRef(opts RefOptions) (string, error) {
+	if !opts.NeedsNameReference() {
+		return fmt.Sprintf("op://%s/%s", sr.VaultID, sr.ItemID), nil
+	}
+	if !IsReferenceSafe(sr.VaultName) {
+		return "", fmt.Errorf("vault %q: %w", sr.VaultName, ErrNameNotReferenceSafe)
+	}
+	if !IsReferenceSafe(sr.ItemName) {
+		return "", fmt.Errorf("item %q: %w", sr.ItemName, ErrNameNotReferenceSafe)
+	}
+	if opts.VaultVar != "" {
+		return fmt.Sprintf("op://${%s}/%s", opts.VaultVar, sr.ItemName), nil
+	}
+	return fmt.Sprintf("op://%s/%s", sr.VaultName, sr.ItemName), nil
+}
+
+// GetItemPath returns the `vaults/<vault>/items/<item>` path of the item, the
+// form used by the 1Password Kubernetes Operator.
+func (sr *SecretReference) GetItemPath(opts RefOptions) (string, error) {
+	itemRef, err := sr.GetItemRef(opts)
+	if err != nil {
+		return "", err
+	}
+	vault, item, _ := strings.Cut(strings.TrimPrefix(itemRef, "op://"), "/")
+	return fmt.Sprintf("vaults/%s/items/%s", vault, item), nil
+}
+
+func (sr *SecretReference) GetFieldRefsFor(opts RefOptions) ([]FieldRef, error) {
+	itemRef, err := sr.GetItemRef(opts)
+	if err != nil {
+		return nil, err
+	}
+	ret := []FieldRef{}
+	for i, label := range sr.FieldLabels {
+		field, err := sr.fieldSegment(i)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, FieldRef{Label: label, Ref: fmt.Sprintf("{{%s/%s}}", itemRef, field)})
+	}
+	return ret, nil
+}
+
+// ValidateNameReference checks that the vault and item names of the reference
+// resolve to that vault and item only. The reference may be the one of a
+// PendingUpload, once its vault ID is known.
+func (c *AccountClient) ValidateNameReference(ctx context.Context, sr *SecretReference) error {
+	if _, err := sr.GetItemRef(RefOptions{RefStyle: RefStyleName}); err != nil {
+		return err
+	}
+
+	vaults, err := c.ListVaults(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list vaults: %w", err)
+	}
+	count := 0
+	for _, vault := range vaults {
+		if strings.EqualFold(vault.Name, sr.VaultName) {
+			count++
+		}
+	}
+	if count > 1 {
+		return fmt.Errorf("vault %q: %w", sr.VaultName, ErrNameNotUnique)
+	}
+
+	// op resolves the item name as `op inject` does, and fails if more than
+	// one item has it, without listing the whole vault
+	vault := NewVaultClient(c.Account, sr.VaultID, WithExecutor(c.executor))
+	err = withRetry(ctx, func() error {
+		var resp ItemResponse
+		return vault.BuildCommand(ctx, "item", "get", sr.ItemName).RunWithJSON(nil, &resp)
+	})
+	if errors.Is(err, ErrMoreThanOneItemFound) {
+		return fmt.Errorf("item %q: %w", sr.ItemName, ErrNameNotUnique)
+	}
+	if errors.Is(err, ErrItemNotFound) {
+		// a pending upload creates the item, which is then the only one
+		// with its name
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get item: %w", err)
+	}
+	return nil
+}
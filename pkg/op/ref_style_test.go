@@ -0,0 +1,178 @@
+package op
+
+import (
+	"context"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetFieldRefsFor(t *testing.T) {
+	sr := &SecretReference{
+		VaultName:   "Development",
+		VaultID:     "vault-id",
+		ItemName:    "my-app",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_PASSWORD", "tls.crt"},
+		FieldIDs:    []string{"DB_PASSWORD", "tls_crt-8d0fcdc3"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     RefOptions
+		itemName string
+		wantRefs []FieldRef
+		wantErr  error
+	}{
+		{
+			name: "id style",
+			opts: RefOptions{RefStyle: RefStyleID},
+			wantRefs: []FieldRef{
+				{Label: "DB_PASSWORD", Ref: "{{op://vault-id/item-id/DB_PASSWORD}}"},
+				{Label: "tls.crt", Ref: "{{op://vault-id/item-id/tls_crt-8d0fcdc3}}"},
+			},
+		},
+		{
+			name: "name style",
+			opts: RefOptions{RefStyle: RefStyleName},
+			wantRefs: []FieldRef{
+				{Label: "DB_PASSWORD", Ref: "{{op://Development/my-app/DB_PASSWORD}}"},
+				{Label: "tls.crt", Ref: "{{op://Development/my-app/tls_crt-8d0fcdc3}}"},
+			},
+		},
+		{
+			name: "vault variable",
+			opts: RefOptions{VaultVar: "APP_ENV"},
+			wantRefs: []FieldRef{
+				{Label: "DB_PASSWORD", Ref: "{{op://${APP_ENV}/my-app/DB_PASSWORD}}"},
+				{Label: "tls.crt", Ref: "{{op://${APP_ENV}/my-app/tls_crt-8d0fcdc3}}"},
+			},
+		},
+		{
+			name:     "vault variable with unsafe item name",
+			opts:     RefOptions{VaultVar: "APP_ENV"},
+			itemName: "my app",
+			wantErr:  ErrNameNotReferenceSafe,
+		},
+		{
+			name:     "name style with unsafe item name",
+			opts:     RefOptions{RefStyle: RefStyleName},
+			itemName: "my-app/.env",
+			wantErr:  ErrNameNotReferenceSafe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref := *sr
+			if tt.itemName != "" {
+				ref.ItemName = tt.itemName
+			}
+			got, err := ref.GetFieldRefsFor(tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetFieldRefsFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.wantRefs) {
+				t.Errorf("GetFieldRefsFor() = %v, want %v", got, tt.wantRefs)
+			}
+		})
+	}
+}
+
+func TestGetItemPath(t *testing.T) {
+	sr := &SecretReference{
+		VaultName: "Development",
+		VaultID:   "vault-id",
+		ItemName:  "my-app",
+		ItemID:    "item-id",
+	}
+
+	tests := []struct {
+		name    string
+		opts    RefOptions
+		want    string
+		wantErr error
+	}{
+		{name: "id style", opts: RefOptions{RefStyle: RefStyleID}, want: "vaults/vault-id/items/item-id"},
+		{name: "name style", opts: RefOptions{RefStyle: RefStyleName}, want: "vaults/Development/items/my-app"},
+		{name: "vault variable", opts: RefOptions{VaultVar: "APP_ENV"}, want: "vaults/${APP_ENV}/items/my-app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sr.GetItemPath(tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetItemPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetItemPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNameReference(t *testing.T) {
+	listVaults := func(stdout string) fakeOpCall {
+		return fakeOpCall{
+			wantArgs: []string{"vault", "list", "--account", "test-account", "--format", "json"},
+			stdout:   stdout,
+		}
+	}
+	getItemArgs := []string{"item", "get", "test-item-1", "--account", "test-account", "--vault", "test-vault-id", "--format", "json"}
+
+	tests := []struct {
+		name     string
+		itemName string
+		calls    []fakeOpCall
+		wantErr  error
+	}{
+		{
+			name:     "unique names",
+			itemName: "test-item-1",
+			calls: []fakeOpCall{
+				listVaults(`[{"id": "test-vault-id", "name": "test-vault-name"}, {"id": "other-id", "name": "other"}]`),
+				{wantArgs: getItemArgs, stdout: `{"id": "test-id-1", "title": "test-item-1"}`},
+			},
+		},
+		{
+			name:     "vault name duplicated",
+			itemName: "test-item-1",
+			calls: []fakeOpCall{
+				listVaults(`[{"id": "test-vault-id", "name": "test-vault-name"}, {"id": "other-id", "name": "Test-Vault-Name"}]`),
+			},
+			wantErr: ErrNameNotUnique,
+		},
+		{
+			name:     "item name duplicated",
+			itemName: "test-item-1",
+			calls: []fakeOpCall{
+				listVaults(`[{"id": "test-vault-id", "name": "test-vault-name"}]`),
+				{wantArgs: getItemArgs, exitCode: 1, stderr: `[ERROR] 2026/10/19 10:00:00 More than one item matches "test-item-1". Try again and specify the item by its ID`},
+			},
+			wantErr: ErrNameNotUnique,
+		},
+		{
+			name:     "item name not reference-safe",
+			itemName: "my app",
+			wantErr:  ErrNameNotReferenceSafe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewAccountClient("test-account", WithExecutor(utilExec.NewExecutor(newFakeOpExec(t, tt.calls))))
+			err := client.ValidateNameReference(context.Background(), &SecretReference{
+				VaultName: "test-vault-name",
+				VaultID:   "test-vault-id",
+				ItemName:  tt.itemName,
+				ItemID:    "test-id-1",
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ValidateNameReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package op
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	utilLogger "github.com/yammerjp/optruck/internal/util/logger"
+)
+
+// retryPolicy is how often and how long a call failing with a transient
+// error is retried. The delay doubles after each attempt, up to maxDelay,
+// and is jittered so that parallel runs do not retry in step.
+type retryPolicy struct {
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+var retry = retryPolicy{
+	attempts:  5,
+	baseDelay: time.Second,
+	maxDelay:  30 * time.Second,
+}
+
+// sleep waits for d unless ctx is done first, replaced in tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// delay returns the wait before the attempt after the given one, between
+// half and all of the exponential backoff.
+func (p retryPolicy) delay(attempt int) time.Duration {
+	d := p.maxDelay
+	if attempt < 32 && p.baseDelay<<(attempt-1) < p.maxDelay {
+		d = p.baseDelay << (attempt - 1)
+	}
+	if d < 2 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
+
+// wait sleeps before retrying after the given attempt failed with err, and
+// returns err if ctx is done first.
+func (p retryPolicy) wait(ctx context.Context, attempt int, err error) error {
+	d := p.delay(attempt)
+	utilLogger.FromContext(ctx).Warn("retrying op after a transient failure", "attempt", attempt, "delay", d, "error", err)
+	if sleepErr := sleep(ctx, d); sleepErr != nil {
+		return fmt.Errorf("%w, and stopped retrying: %w", err, sleepErr)
+	}
+	return nil
+}
+
+// withRetry runs read, which must not change anything in 1Password, again
+// while it fails with a transient error.
+func withRetry(ctx context.Context, read func() error) error {
+	err := read()
+	for attempt := 1; err != nil && isTransient(err) && attempt < retry.attempts; attempt++ {
+		if err := retry.wait(ctx, attempt, err); err != nil {
+			return err
+		}
+		err = read()
+	}
+	return err
+}
+
+// withWriteRetry runs write again while it fails with a transient error, but
+// only after landed tells that the failed write did not take effect, since
+// op may fail after 1Password has stored the change. landed is a read and is
+// retried on its own; if it fails, the write is not retried.
+func withWriteRetry(ctx context.Context, write func() error, landed func() (bool, error)) error {
+	err := write()
+	for attempt := 1; err != nil && isTransient(err) && attempt < retry.attempts; attempt++ {
+		if err := retry.wait(ctx, attempt, err); err != nil {
+			return err
+		}
+		ok, readErr := landed()
+		if readErr != nil {
+			return fmt.Errorf("%w, and failed to check whether it was written: %w", err, readErr)
+		}
+		if ok {
+			utilLogger.FromContext(ctx).Debug("the failed write had taken effect", "attempt", attempt)
+			return nil
+		}
+		err = write()
+	}
+	return err
+}
+
+// hasFields reports whether the item holds every field of envPairs with
+// the same value.
+func hasFields(item *ItemResponse, envPairs map[string]string) bool {
+	fields := userFields(*item)
+	for k, v := range envPairs {
+		if value, ok := fields[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package op
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+)
+
+const (
+	rateLimitedStderr = "[ERROR] 2026/10/19 10:00:00 Too many requests (429). Please try again later"
+	networkStderr     = "[ERROR] 2026/10/19 10:00:00 Post \"https://my.1password.com/api/v2/items\": read tcp: connection reset by peer"
+	createdItemJSON   = `{"id": "ITEM", "title": "my-app", "version": 1, "vault": {"id": "VAULT", "name": "Development"}, "fields": [{"id": "FOO", "type": "CONCEALED", "label": "FOO", "value": "foo"}]}`
+)
+
+// fakeSleep records the delays instead of sleeping.
+func fakeSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	delays := []time.Duration{}
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = orig })
+	return &delays
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := retryPolicy{attempts: 5, baseDelay: time.Second, maxDelay: 4 * time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 1, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 2, min: time.Second, max: 2 * time.Second},
+		{attempt: 3, min: 2 * time.Second, max: 4 * time.Second},
+		{attempt: 4, min: 2 * time.Second, max: 4 * time.Second},
+		{attempt: 64, min: 2 * time.Second, max: 4 * time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if d := p.delay(tt.attempt); d < tt.min || d >= tt.max {
+				t.Errorf("delay(%d) = %s, want in [%s, %s)", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	getArgs := []string{"item", "get", "my-app", "--reveal", "--account", "my.1password.com", "--vault", "Development", "--format", "json"}
+	createArgs := []string{"item", "create", "--account", "my.1password.com", "--vault", "Development", "--format", "json"}
+	editArgs := []string{"item", "edit", "my-app", "--account", "my.1password.com", "--vault", "Development", "--format", "json"}
+	itemNotFoundStderr := `[ERROR] 2026/10/19 10:00:00 "my-app" isn't an item in the "Development" vault.`
+
+	tests := []struct {
+		name       string
+		calls      []fakeOpCall
+		run        func(c *ItemClient) error
+		wantErr    error
+		wantSleeps int
+	}{
+		{
+			name: "read retried until it succeeds",
+			calls: []fakeOpCall{
+				{wantArgs: getArgs, exitCode: 1, stderr: rateLimitedStderr},
+				{wantArgs: getArgs, exitCode: 1, stderr: networkStderr},
+				{wantArgs: getArgs, stdout: createdItemJSON},
+			},
+			run: func(c *ItemClient) error {
+				_, err := c.GetItem(context.Background())
+				return err
+			},
+			wantSleeps: 2,
+		},
+		{
+			name: "read not retried on a permanent failure",
+			calls: []fakeOpCall{
+				{wantArgs: getArgs, exitCode: 1, stderr: itemNotFoundStderr},
+			},
+			run: func(c *ItemClient) error {
+				_, err := c.GetItem(context.Background())
+				return err
+			},
+			wantErr: ErrItemNotFound,
+		},
+		{
+			name: "read gives up after the attempts",
+			calls: []fakeOpCall{
+				{wantArgs: getArgs, exitCode: 1, stderr: rateLimitedStderr},
+				{wantArgs: getArgs, exitCode: 1, stderr: rateLimitedStderr},
+				{wantArgs: getArgs, exitCode: 1, stderr: rateLimitedStderr},
+				{wantArgs: getArgs, exitCode: 1, stderr: rateLimitedStderr},
+				{wantArgs: getArgs, exitCode: 1, stderr: rateLimitedStderr},
+			},
+			run: func(c *ItemClient) error {
+				_, err := c.GetItem(context.Background())
+				return err
+			},
+			wantErr:    ErrRateLimited,
+			wantSleeps: 4,
+		},
+		{
+			name: "create not retried when it landed",
+			calls: []fakeOpCall{
+				{wantArgs: createArgs, exitCode: 1, stderr: networkStderr},
+				{wantArgs: getArgs, stdout: createdItemJSON},
+			},
+			run: func(c *ItemClient) error {
+				ref, err := c.CreateItem(context.Background(), map[string]string{"FOO": "foo"})
+				if err == nil && ref.ItemID != "ITEM" {
+					t.Errorf("ItemID = %q, want ITEM", ref.ItemID)
+				}
+				return err
+			},
+			wantSleeps: 1,
+		},
+		{
+			name: "create retried when it did not land",
+			calls: []fakeOpCall{
+				{wantArgs: createArgs, exitCode: 1, stderr: rateLimitedStderr},
+				{wantArgs: getArgs, exitCode: 1, stderr: itemNotFoundStderr},
+				{wantArgs: createArgs, stdout: createdItemJSON},
+			},
+			run: func(c *ItemClient) error {
+				_, err := c.CreateItem(context.Background(), map[string]string{"FOO": "foo"})
+				return err
+			},
+			wantSleeps: 1,
+		},
+		{
+			name: "edit retried when the item still has the old values",
+			calls: []fakeOpCall{
+				{wantArgs: editArgs, exitCode: 1, stderr: networkStderr},
+				{wantArgs: getArgs, stdout: createdItemJSON},
+				{wantArgs: editArgs, stdout: createdItemJSON},
+			},
+			run: func(c *ItemClient) error {
+				_, err := c.EditItem(context.Background(), nil, map[string]string{"FOO": "foo2"})
+				return err
+			},
+			wantSleeps: 1,
+		},
+		{
+			name: "edit not retried when the check fails",
+			calls: []fakeOpCall{
+				{wantArgs: editArgs, exitCode: 1, stderr: networkStderr},
+				{wantArgs: getArgs, exitCode: 1, stderr: itemNotFoundStderr},
+			},
+			run: func(c *ItemClient) error {
+				_, err := c.EditItem(context.Background(), nil, map[string]string{"FOO": "foo2"})
+				return err
+			},
+			wantErr:    ErrNetwork,
+			wantSleeps: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays := fakeSleep(t)
+			fakeExec := newFakeOpExec(t, tt.calls)
+
+			err := tt.run(NewItemClient("my.1password.com", "Development", "my-app", WithExecutor(utilExec.NewExecutor(fakeExec))))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if fakeExec.CommandCalls != len(tt.calls) {
+				t.Errorf("ran op %d times, want %d", fakeExec.CommandCalls, len(tt.calls))
+			}
+			if len(*delays) != tt.wantSleeps {
+				t.Errorf("slept %d times, want %d", len(*delays), tt.wantSleeps)
+			}
+		})
+	}
+}
@@ -1,6 +1,20 @@
 package op
 
-import "fmt"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// referenceSafeRegex matches the names that can be used as is in a secret
+// reference. Other labels (e.g. `tls.crt`, `a/b` or `DB PASSWORD`) are
+// referenced by the field ID instead.
+var referenceSafeRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var ErrUnresolvableFieldLabel = errors.New("field cannot be referenced unambiguously, rename the field or remove the duplicated one in 1Password")
 
 type SecretReference struct {
 	Account     string
@@ -9,11 +23,19 @@ type SecretReference struct {
 	ItemName    string
 	ItemID      string
 	FieldLabels []string
+	// FieldIDs[i] is the ID of the field labeled FieldLabels[i]
+	FieldIDs []string
 }
 
 type FieldRef struct {
 	Label string
-	Ref   string
+	// Ref is the reference wrapped in braces, as `op inject` expects
+	Ref string
+}
+
+// URI returns the bare `op://` reference, as `op run` and `op read` expect.
+func (f FieldRef) URI() string {
+	return strings.TrimSuffix(strings.TrimPrefix(f.Ref, "{{"), "}}")
 }
 
 type ItemResponse struct {
@@ -24,36 +46,110 @@ type ItemResponse struct {
 		ID   string `json:"id"`
 		Name string `json:"name"`
 	} `json:"vault"`
-	Category              string `json:"category"`
-	CreatedAt             string `json:"created_at"`
-	UpdatedAt             string `json:"updated_at"`
-	AdditionalInformation string `json:"additional_information"`
-	Fields                []struct {
-		ID              string `json:"id"`
-		Type            string `json:"type"`
-		Purpose         string `json:"purpose"`
-		Label           string `json:"label"`
-		Value           string `json:"value"`
-		Reference       string `json:"reference"`
-		PasswordDetails struct {
-			Strength string `json:"strength"`
-		} `json:"password_details"`
-	} `json:"fields"`
+	Category              string              `json:"category"`
+	CreatedAt             string              `json:"created_at"`
+	UpdatedAt             string              `json:"updated_at"`
+	AdditionalInformation string              `json:"additional_information"`
+	Sections              []ItemSection       `json:"sections"`
+	Fields                []ItemResponseField `json:"fields"`
+}
+
+type ItemResponseField struct {
+	ID              string       `json:"id"`
+	Section         *ItemSection `json:"section"`
+	Type            string       `json:"type"`
+	Purpose         string       `json:"purpose"`
+	Label           string       `json:"label"`
+	Value           string       `json:"value"`
+	Reference       string       `json:"reference"`
+	PasswordDetails struct {
+		Strength string `json:"strength"`
+	} `json:"password_details"`
+}
+
+func IsReferenceSafe(name string) bool {
+	return referenceSafeRegex.MatchString(name)
+}
+
+func (sr *SecretReference) GetFieldRefs() ([]FieldRef, error) {
+	return sr.GetFieldRefsFor(RefOptions{})
+}
+
+// fieldSegment returns the label of the i-th field if it resolves to that
+// field only, otherwise the field ID.
+func (sr *SecretReference) fieldSegment(i int) (string, error) {
+	label := sr.FieldLabels[i]
+	if IsReferenceSafe(label) && !sr.collides(label, i) {
+		return label, nil
+	}
+	id := ""
+	if i < len(sr.FieldIDs) {
+		id = sr.FieldIDs[i]
+	}
+	if id == "" || !IsReferenceSafe(id) || sr.collides(id, i) {
+		return "", fmt.Errorf("%w: %q", ErrUnresolvableFieldLabel, label)
+	}
+	return id, nil
 }
 
-func (sr *SecretReference) GetFieldRefs() []FieldRef {
-	ret := []FieldRef{}
-	for _, field := range sr.FieldLabels {
-		ret = append(ret, FieldRef{Label: field, Ref: fmt.Sprintf("{{op://%s/%s/%s}}", sr.VaultID, sr.ItemID, field)})
+// collides reports whether the name also matches another field, since
+// 1Password resolves a reference segment by label or ID, case-insensitively.
+func (sr *SecretReference) collides(name string, i int) bool {
+	for j, label := range sr.FieldLabels {
+		if j == i {
+			continue
+		}
+		if strings.EqualFold(label, name) {
+			return true
+		}
+		if j < len(sr.FieldIDs) && strings.EqualFold(sr.FieldIDs[j], name) {
+			return true
+		}
 	}
-	return ret
+	return false
+}
+
+// FieldIDs returns the field IDs optruck assigns to the given labels. A label
+// is used as its own ID when it is reference-safe and unique; otherwise the ID
+// is derived from a hash of the label, so the same label always gets the same
+// ID across items and vaults.
+func FieldIDs(labels []string) map[string]string {
+	ids := make(map[string]string, len(labels))
+	for i, label := range labels {
+		unique := true
+		for j, other := range labels {
+			if i != j && strings.EqualFold(label, other) {
+				unique = false
+				break
+			}
+		}
+		if IsReferenceSafe(label) && unique {
+			ids[label] = label
+			continue
+		}
+		ids[label] = hashedFieldID(label)
+	}
+	return ids
+}
+
+func hashedFieldID(label string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if r < 0x80 && IsReferenceSafe(string(r)) {
+			return r
+		}
+		return '_'
+	}, label)
+	sum := sha256.Sum256([]byte(label))
+	return sanitized + "-" + hex.EncodeToString(sum[:])[:8]
 }
 
 func (c *AccountClient) BuildSecretReference(resp ItemResponse) *SecretReference {
 	fieldLabels := []string{}
+	fieldIDs := []string{}
 	for _, field := range resp.Fields {
 		if field.Purpose == "" {
 			fieldLabels = append(fieldLabels, field.Label)
+			fieldIDs = append(fieldIDs, field.ID)
 		}
 	}
 	return &SecretReference{
@@ -63,5 +159,6 @@ func (c *AccountClient) BuildSecretReference(resp ItemResponse) *SecretReference
 		ItemName:    resp.Title,
 		ItemID:      resp.ID,
 		FieldLabels: fieldLabels,
+		FieldIDs:    fieldIDs,
 	}
 }
@@ -0,0 +1,192 @@
+package op
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGetFieldRefs(t *testing.T) {
+	tests := []struct {
+		name        string
+		fieldLabels []string
+		fieldIDs    []string
+		wantRefs    []FieldRef
+		wantErr     error
+	}{
+		{
+			name:        "labels used as is",
+			fieldLabels: []string{"DB_USER", "db-pass", "Key0"},
+			fieldIDs:    []string{"DB_USER", "db-pass", "Key0"},
+			wantRefs: []FieldRef{
+				{Label: "DB_USER", Ref: "{{op://vault-id/item-id/DB_USER}}"},
+				{Label: "db-pass", Ref: "{{op://vault-id/item-id/db-pass}}"},
+				{Label: "Key0", Ref: "{{op://vault-id/item-id/Key0}}"},
+			},
+		},
+		{
+			name:        "labels with special characters use field IDs",
+			fieldLabels: []string{"tls.crt", "a/b", "DB PASSWORD"},
+			fieldIDs:    []string{"tlscrtid", "abid", "dbpasswordid"},
+			wantRefs: []FieldRef{
+				{Label: "tls.crt", Ref: "{{op://vault-id/item-id/tlscrtid}}"},
+				{Label: "a/b", Ref: "{{op://vault-id/item-id/abid}}"},
+				{Label: "DB PASSWORD", Ref: "{{op://vault-id/item-id/dbpasswordid}}"},
+			},
+		},
+		{
+			name:        "duplicate labels use field IDs",
+			fieldLabels: []string{"FOO", "FOO", "BAR"},
+			fieldIDs:    []string{"fooid1", "fooid2", "BAR"},
+			wantRefs: []FieldRef{
+				{Label: "FOO", Ref: "{{op://vault-id/item-id/fooid1}}"},
+				{Label: "FOO", Ref: "{{op://vault-id/item-id/fooid2}}"},
+				{Label: "BAR", Ref: "{{op://vault-id/item-id/BAR}}"},
+			},
+		},
+		{
+			name:        "labels differing only in case use field IDs",
+			fieldLabels: []string{"foo", "FOO"},
+			fieldIDs:    []string{"fooid1", "fooid2"},
+			wantRefs: []FieldRef{
+				{Label: "foo", Ref: "{{op://vault-id/item-id/fooid1}}"},
+				{Label: "FOO", Ref: "{{op://vault-id/item-id/fooid2}}"},
+			},
+		},
+		{
+			name:        "label matching the ID of another field uses its ID",
+			fieldLabels: []string{"FOO", "BAR"},
+			fieldIDs:    []string{"fooid", "FOO"},
+			wantRefs: []FieldRef{
+				{Label: "FOO", Ref: "{{op://vault-id/item-id/fooid}}"},
+				{Label: "BAR", Ref: "{{op://vault-id/item-id/BAR}}"},
+			},
+		},
+		{
+			name:        "special characters without field IDs",
+			fieldLabels: []string{"tls.crt"},
+			wantErr:     ErrUnresolvableFieldLabel,
+		},
+		{
+			name:        "duplicate labels with the same field IDs",
+			fieldLabels: []string{"FOO", "FOO"},
+			fieldIDs:    []string{"FOO", "FOO"},
+			wantErr:     ErrUnresolvableFieldLabel,
+		},
+		{
+			name:        "field ID with special characters",
+			fieldLabels: []string{"a b"},
+			fieldIDs:    []string{"a b"},
+			wantErr:     ErrUnresolvableFieldLabel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := &SecretReference{
+				VaultID:     "vault-id",
+				ItemID:      "item-id",
+				FieldLabels: tt.fieldLabels,
+				FieldIDs:    tt.fieldIDs,
+			}
+			got, err := sr.GetFieldRefs()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetFieldRefs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.wantRefs) {
+				t.Errorf("GetFieldRefs() = %v, want %v", got, tt.wantRefs)
+			}
+		})
+	}
+}
+
+// TestFieldIDsResolve checks that every key a Kubernetes Secret
+// ([-._a-zA-Z0-9]+) or a dotenv file (letters, numbers, '_' and '.') may
+// contain gets a reference that points to its own field only.
+func TestFieldIDsResolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		labels    []string
+		wantNames map[string]string
+	}{
+		{
+			name:   "k8s secret keys",
+			labels: []string{"lower", "UPPER", "0123", "dash-key", "under_score", "tls.crt", ".dockerconfigjson", "a-b_c.d"},
+			wantNames: map[string]string{
+				"lower":             "lower",
+				"UPPER":             "UPPER",
+				"0123":              "0123",
+				"dash-key":          "dash-key",
+				"under_score":       "under_score",
+				"tls.crt":           "tls_crt-",
+				".dockerconfigjson": "_dockerconfigjson-",
+				"a-b_c.d":           "a-b_c_d-",
+			},
+		},
+		{
+			name:   "dotenv keys",
+			labels: []string{"KEY", "KEY.SUB", "ÜBER", "数字", "_LEADING"},
+			wantNames: map[string]string{
+				"KEY":      "KEY",
+				"KEY.SUB":  "KEY_SUB-",
+				"ÜBER":     "_BER-",
+				"数字":       "__-",
+				"_LEADING": "_LEADING",
+			},
+		},
+		{
+			name:   "labels colliding after sanitizing",
+			labels: []string{"a.b", "a_b", "a b", "a/b"},
+			wantNames: map[string]string{
+				"a.b": "a_b-",
+				"a_b": "a_b",
+				"a b": "a_b-",
+				"a/b": "a_b-",
+			},
+		},
+		{
+			name:   "labels differing only in case",
+			labels: []string{"token", "TOKEN"},
+			wantNames: map[string]string{
+				"token": "token-",
+				"TOKEN": "TOKEN-",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := FieldIDs(tt.labels)
+			sr := &SecretReference{VaultID: "vault-id", ItemID: "item-id"}
+			for _, label := range tt.labels {
+				id := ids[label]
+				if !IsReferenceSafe(id) {
+					t.Errorf("FieldIDs()[%q] = %q, not reference-safe", label, id)
+				}
+				want := tt.wantNames[label]
+				if want[len(want)-1] == '-' {
+					// hashed ID: sanitized label + "-" + 8 hex digits
+					if len(id) != len(want)+8 || id[:len(want)] != want {
+						t.Errorf("FieldIDs()[%q] = %q, want %q + hash", label, id, want)
+					}
+				} else if id != want {
+					t.Errorf("FieldIDs()[%q] = %q, want %q", label, id, want)
+				}
+				sr.FieldLabels = append(sr.FieldLabels, label)
+				sr.FieldIDs = append(sr.FieldIDs, id)
+			}
+
+			refs, err := sr.GetFieldRefs()
+			if err != nil {
+				t.Fatalf("GetFieldRefs() error = %v", err)
+			}
+			seen := map[string]bool{}
+			for _, ref := range refs {
+				if seen[ref.Ref] {
+					t.Errorf("GetFieldRefs() returned %s twice", ref.Ref)
+				}
+				seen[ref.Ref] = true
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package op
+
+import "os"
+
+// ServiceAccountTokenEnv is the environment variable that signs op in as a
+// service account, e.g. in CI.
+const ServiceAccountTokenEnv = "OP_SERVICE_ACCOUNT_TOKEN"
+
+// IsServiceAccount reports whether op runs as a service account. A service
+// account belongs to a single account, so op is run without --account, and
+// it only sees the vaults it has been granted.
+func IsServiceAccount() bool {
+	return os.Getenv(ServiceAccountTokenEnv) != ""
+}
@@ -1,29 +1,157 @@
 package op
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"log/slog"
+	"maps"
+	"slices"
+
+	utilLogger "github.com/yammerjp/optruck/internal/util/logger"
 )
 
 var ErrMoreThanOneItemFound = errors.New("more than one item found, please specify another item name")
 var ErrItemAlreadyExists = errors.New("item already exists, use --overwrite to update")
 
-func (c *ItemClient) UploadItem(envPairs map[string]string, overwrite bool) (*SecretReference, error) {
-	refs, err := c.FilterItems(c.ItemName)
+// Upload is the item written by UploadItem, with what RevertUpload needs to
+// undo it.
+type Upload struct {
+	*SecretReference
+	// Previous is the item before it was overwritten, nil if it was created.
+	Previous *ItemResponse
+}
+
+func (u *Upload) Created() bool {
+	return u.Previous == nil
+}
+
+// AddedFields returns the labels of the fields an overwrite added to the
+// item, which RevertUpload keeps with their new values.
+func (u *Upload) AddedFields() []string {
+	if u.Created() {
+		return nil
+	}
+	previous := userFields(*u.Previous)
+	added := []string{}
+	for _, label := range u.FieldLabels {
+		if _, ok := previous[label]; !ok {
+			added = append(added, label)
+		}
+	}
+	return added
+}
+
+// PendingUpload is an upload looked up by PrepareUpload but not written yet.
+// Its SecretReference is the item as it will be once written, so that the
+// templates can be checked before anything changes in 1Password. The vault
+// and item IDs of a new item are only known once 1Password creates it, and
+// are left empty.
+type PendingUpload struct {
+	*SecretReference
+	envPairs map[string]string
+	// current is the item to overwrite, nil if it is created
+	current *ItemResponse
+}
+
+func (p *PendingUpload) Creates() bool {
+	return p.current == nil
+}
+
+func (c *ItemClient) UploadItem(ctx context.Context, envPairs map[string]string, overwrite bool) (*Upload, error) {
+	pending, err := c.PrepareUpload(ctx, envPairs, overwrite)
 	if err != nil {
-		return nil, fmt.Errorf("failed to filter items: %w. Please check the item name and try again.", err)
+		return nil, err
+	}
+	return c.CommitUpload(ctx, pending)
+}
+
+// PrepareUpload looks up the item UploadItem would write envPairs to, and
+// fails as UploadItem would if it cannot be written.
+func (c *ItemClient) PrepareUpload(ctx context.Context, envPairs map[string]string, overwrite bool) (*PendingUpload, error) {
+	// op looks the item up by its name or ID, which is much faster than
+	// listing a large vault
+	current, err := c.GetItem(ctx)
+	if errors.Is(err, ErrItemNotFound) {
+		utilLogger.FromContext(ctx).Debug("item not found, creating new item", "item", c.ItemName)
+		return &PendingUpload{SecretReference: c.newItemReference(envPairs), envPairs: envPairs}, nil
 	}
-	if len(refs) == 0 {
-		slog.Debug("item not found, creating new item", "item", c.ItemName)
-		return c.CreateItem(envPairs)
+	if errors.Is(err, ErrMoreThanOneItemFound) {
+		return nil, err
 	}
-	if len(refs) == 1 {
-		slog.Debug("item found, updating existing item", "item", c.ItemName)
-		if !overwrite {
-			return nil, ErrItemAlreadyExists
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the item: %w. Please check the item name and try again.", err)
+	}
+
+	utilLogger.FromContext(ctx).Debug("item found, updating existing item", "item", c.ItemName)
+	if !overwrite {
+		return nil, ErrItemAlreadyExists
+	}
+	return &PendingUpload{SecretReference: c.editedReference(current, envPairs), envPairs: envPairs, current: current}, nil
+}
+
+// CommitUpload writes the upload prepared by PrepareUpload.
+func (c *ItemClient) CommitUpload(ctx context.Context, pending *PendingUpload) (*Upload, error) {
+	if pending.Creates() {
+		ref, err := c.CreateItem(ctx, pending.envPairs)
+		if err != nil {
+			return nil, err
 		}
-		return c.EditItem(envPairs)
+		return &Upload{SecretReference: ref}, nil
+	}
+	if err := c.SaveSnapshot(ctx, pending.current); err != nil {
+		return nil, fmt.Errorf("failed to save the item history before overwriting: %w", err)
+	}
+	ref, err := c.EditItem(ctx, pending.current, pending.envPairs)
+	if err != nil {
+		return nil, err
+	}
+	return &Upload{SecretReference: ref, Previous: pending.current}, nil
+}
+
+// newItemReference returns the reference of the item CreateItem makes.
+func (c *ItemClient) newItemReference(envPairs map[string]string) *SecretReference {
+	labels := slices.Sorted(maps.Keys(envPairs))
+	ids := FieldIDs(labels)
+	ref := &SecretReference{Account: c.Account, VaultName: c.Vault, ItemName: c.ItemName}
+	for _, label := range labels {
+		ref.FieldLabels = append(ref.FieldLabels, label)
+		ref.FieldIDs = append(ref.FieldIDs, ids[label])
+	}
+	return ref
+}
+
+// editedReference returns the reference of current once EditItem has set
+// envPairs: the fields it has keep their IDs, and the new ones follow.
+func (c *ItemClient) editedReference(current *ItemResponse, envPairs map[string]string) *SecretReference {
+	ref := c.BuildSecretReference(*current)
+	added := []string{}
+	for _, label := range slices.Sorted(maps.Keys(envPairs)) {
+		if !slices.Contains(ref.FieldLabels, label) {
+			added = append(added, label)
+		}
+	}
+	ids := editFieldIDs(current, added)
+	for _, label := range added {
+		ref.FieldLabels = append(ref.FieldLabels, label)
+		ref.FieldIDs = append(ref.FieldIDs, ids[label])
+	}
+	return ref
+}
+
+// RevertUpload undoes UploadItem. A created item is archived, so that it can
+// still be restored from the archive, and an overwritten one gets its previous
+// field values back. Like Rollback, the fields added by the upload are kept.
+func (c *ItemClient) RevertUpload(ctx context.Context, upload *Upload) error {
+	if upload.Created() {
+		defer cacheFrom(ctx).forgetItems()
+		cmd := c.BuildCommand(ctx, "item", "delete", upload.ItemID, "--archive")
+		if err := cmd.Run(nil, nil); err != nil {
+			return fmt.Errorf("failed to archive item %s: %w", upload.ItemID, err)
+		}
+		return nil
+	}
+	if _, err := c.EditItem(ctx, upload.Previous, userFields(*upload.Previous)); err != nil {
+		return fmt.Errorf("failed to restore the previous fields of item %s: %w", upload.ItemID, err)
 	}
-	return nil, ErrMoreThanOneItemFound
+	return nil
 }
@@ -0,0 +1,140 @@
+package op
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	utilExec "github.com/yammerjp/optruck/internal/util/exec"
+)
+
+func TestUploadItem(t *testing.T) {
+	getArgs := []string{"item", "get", "test-item", "--reveal", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"}
+	createArgs := []string{"item", "create", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"}
+
+	tests := []struct {
+		name        string
+		overwrite   bool
+		calls       []fakeOpCall
+		wantCreated bool
+		wantErr     error
+	}{
+		{
+			name: "create a new item",
+			calls: []fakeOpCall{
+				{wantArgs: getArgs, exitCode: 1, stderr: `[ERROR] 2026/10/19 10:00:00 "test-item" isn't an item in the "test-vault-name" vault.`},
+				{wantArgs: createArgs, stdout: mockCreateStdoutSuccess},
+			},
+			wantCreated: true,
+		},
+		{
+			name:      "overwrite the existing item",
+			overwrite: true,
+			calls: []fakeOpCall{
+				{wantArgs: getArgs, stdout: mockGetCurrentStdout},
+				{wantArgs: createArgs, stdout: `{"id": "snapshot-3"}`},
+				{wantArgs: []string{"item", "delete", "snapshot-3", "--archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"}},
+				{wantArgs: []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"}, stdout: mockEditStdoutSuccess},
+			},
+		},
+		{
+			name: "existing item without overwrite",
+			calls: []fakeOpCall{
+				{wantArgs: getArgs, stdout: mockGetCurrentStdout},
+			},
+			wantErr: ErrItemAlreadyExists,
+		},
+		{
+			name:      "more than one item with the name",
+			overwrite: true,
+			calls: []fakeOpCall{
+				{wantArgs: getArgs, exitCode: 1, stderr: "[ERROR] 2026/10/19 10:00:00 More than one item matches \"test-item\". Try again and specify the item by its ID:\n\t* for the item \"test-item\" in vault test-vault-name: id-1\n\t* for the item \"test-item\" in vault test-vault-name: id-2"},
+			},
+			wantErr: ErrMoreThanOneItemFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeExec := newFakeOpExec(t, tt.calls)
+
+			client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+			upload, err := client.UploadItem(context.Background(), map[string]string{"FOO": "foo4", "BAR": "bar4"}, tt.overwrite)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("UploadItem() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UploadItem() error = %v", err)
+			}
+			if upload.Created() != tt.wantCreated {
+				t.Errorf("Created() = %v, want %v", upload.Created(), tt.wantCreated)
+			}
+			if fakeExec.CommandCalls != len(tt.calls) {
+				t.Errorf("ran op %d times, want %d", fakeExec.CommandCalls, len(tt.calls))
+			}
+		})
+	}
+}
+
+func TestRevertUpload(t *testing.T) {
+	t.Run("archive the created item", func(t *testing.T) {
+		fakeExec := newFakeOpExec(t, []fakeOpCall{
+			{
+				wantArgs: []string{"item", "delete", "test-id", "--archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+			},
+		})
+
+		client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+		upload := &Upload{SecretReference: &SecretReference{ItemID: "test-id"}}
+		if err := client.RevertUpload(context.Background(), upload); err != nil {
+			t.Fatalf("RevertUpload() error = %v", err)
+		}
+		if fakeExec.CommandCalls != 1 {
+			t.Errorf("expected 1 command, got %d", fakeExec.CommandCalls)
+		}
+	})
+
+	t.Run("restore the overwritten fields", func(t *testing.T) {
+		var previous ItemResponse
+		if err := json.Unmarshal([]byte(mockGetCurrentStdout), &previous); err != nil {
+			t.Fatal(err)
+		}
+		var editStdin []byte
+		fakeExec := newFakeOpExec(t, []fakeOpCall{
+			{
+				wantArgs: []string{"item", "edit", "test-item", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+				stdout:   mockEditStdoutSuccess,
+				stdin:    &editStdin,
+			},
+		})
+
+		client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+		upload := &Upload{SecretReference: &SecretReference{ItemID: "test-id"}, Previous: &previous}
+		if err := client.RevertUpload(context.Background(), upload); err != nil {
+			t.Fatalf("RevertUpload() error = %v", err)
+		}
+		wantStdin := `{"sections":[{"id":"optruck","label":"optruck"}],"fields":[{"id":"BAR","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"BAR","value":"bar3"},{"id":"FOO","section":{"id":"optruck","label":"optruck"},"type":"CONCEALED","label":"FOO","value":"foo3"}]}`
+		if string(editStdin) != wantStdin {
+			t.Errorf("edit stdin JSON = %s, want %s", editStdin, wantStdin)
+		}
+	})
+
+	t.Run("archive fails", func(t *testing.T) {
+		fakeExec := newFakeOpExec(t, []fakeOpCall{
+			{
+				wantArgs: []string{"item", "delete", "test-id", "--archive", "--account", "test-account", "--vault", "test-vault-name", "--format", "json"},
+				exitCode: 1,
+			},
+		})
+
+		client := NewItemClient("test-account", "test-vault-name", "test-item", WithExecutor(utilExec.NewExecutor(fakeExec)))
+		upload := &Upload{SecretReference: &SecretReference{ItemID: "test-id"}}
+		if err := client.RevertUpload(context.Background(), upload); err == nil {
+			t.Error("RevertUpload() expected error but got nil")
+		}
+	})
+}
@@ -1,5 +1,11 @@
 package op
 
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
 type Vault struct {
 	ID             string `json:"id"`
 	Name           string `json:"name"`
@@ -9,11 +15,31 @@ type Vault struct {
 	Items          int    `json:"items"`
 }
 
-func (c *AccountClient) ListVaults() ([]Vault, error) {
-	cmd := c.BuildCommand("vault", "list")
-	var resp []Vault
-	if err := cmd.RunWithJSON(nil, &resp); err != nil {
+func (c *AccountClient) ListVaults(ctx context.Context) ([]Vault, error) {
+	return cacheFrom(ctx).listVaults(c.Account, func() ([]Vault, error) {
+		var resp []Vault
+		err := withRetry(ctx, func() error {
+			return c.BuildCommand(ctx, "vault", "list").RunWithJSON(nil, &resp)
+		})
+		return resp, err
+	})
+}
+
+// GetVault returns the vault of the client, given by its ID or its name.
+func (c *VaultClient) GetVault(ctx context.Context) (*Vault, error) {
+	vaults, err := c.ListVaults(ctx)
+	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	for i := range vaults {
+		if vaults[i].ID == c.Vault {
+			return &vaults[i], nil
+		}
+	}
+	for i := range vaults {
+		if strings.EqualFold(vaults[i].Name, c.Vault) {
+			return &vaults[i], nil
+		}
+	}
+	return nil, fmt.Errorf("vault %q: %w", c.Vault, ErrVaultNotFound)
 }
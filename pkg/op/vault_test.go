@@ -1,6 +1,7 @@
 package op
 
 import (
+	"context"
 	"testing"
 
 	utilExec "github.com/yammerjp/optruck/internal/util/exec"
@@ -91,10 +92,9 @@ func TestListVaults(t *testing.T) {
 				},
 			}
 
-			client := NewAccountClient(tt.account)
-			utilExec.SetExec(fakeExec)
+			client := NewAccountClient(tt.account, WithExecutor(utilExec.NewExecutor(fakeExec)))
 
-			got, err := client.ListVaults()
+			got, err := client.ListVaults(context.Background())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListVaults() error = %v, wantErr %v", err, tt.wantErr)
 				return
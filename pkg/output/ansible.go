@@ -0,0 +1,131 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+const DefaultAnsibleOutputPath = "onepassword_vars.yml"
+
+const (
+	// AnsibleLookupOnePassword reads the fields with the
+	// community.general.onepassword lookup.
+	AnsibleLookupOnePassword = "onepassword"
+	// AnsibleLookupOpRead reads the op:// references with `op read` through
+	// the pipe lookup.
+	AnsibleLookupOpRead = "op-read"
+
+	AnsibleVarCaseLower = "lower"
+	AnsibleVarCaseKeep  = "keep"
+)
+
+// AnsibleVarsDest writes an Ansible vars file of 1Password lookups.
+type AnsibleVarsDest struct {
+	Path      string
+	Lookup    string
+	VarPrefix string
+	VarCase   string
+	op.RefOptions
+}
+
+func (d *AnsibleVarsDest) GetPath() string {
+	return d.Path
+}
+
+func (d *AnsibleVarsDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+var ansibleInvalidVarChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+var ansibleVarRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// VarName returns the Ansible variable name of a field label: the label with
+// VarCase applied, characters other than letters, digits and '_' replaced by
+// '_', and VarPrefix prepended.
+func (d *AnsibleVarsDest) VarName(label string) (string, error) {
+	name := label
+	switch d.VarCase {
+	case "", AnsibleVarCaseLower:
+		name = strings.ToLower(name)
+	case AnsibleVarCaseKeep:
+	default:
+		return "", fmt.Errorf("invalid variable case: %s, use %s or %s", d.VarCase, AnsibleVarCaseLower, AnsibleVarCaseKeep)
+	}
+	name = d.VarPrefix + ansibleInvalidVarChars.ReplaceAllString(name, "_")
+	if !ansibleVarRegex.MatchString(name) {
+		return "", fmt.Errorf("%q is not a valid Ansible variable name, please set a prefix starting with a letter", name)
+	}
+	return name, nil
+}
+
+// pythonQuote returns s as a single-quoted Python (Jinja) string literal.
+func pythonQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+type ansibleTemplateData struct {
+	*op.SecretReference
+	Dest *AnsibleVarsDest
+	Vars []templateVariable
+}
+
+func (d *AnsibleVarsDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	vault, item := pythonQuote(secretReference.VaultID), pythonQuote(secretReference.ItemID)
+	if d.NeedsNameReference() {
+		vault, item = pythonQuote(secretReference.VaultName), pythonQuote(secretReference.ItemName)
+	}
+	if d.VaultVar != "" {
+		vault = d.VaultVar
+	}
+
+	vars := make([]templateVariable, 0, len(refs))
+	seen := map[string]string{}
+	for _, ref := range refs {
+		name, err := d.VarName(ref.Label)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("%q and %q are both named %s, please use --ansible-var-case keep", other, ref.Label, name)
+		}
+		seen[name] = ref.Label
+
+		var lookup string
+		switch d.Lookup {
+		case "", AnsibleLookupOnePassword:
+			lookup = fmt.Sprintf("lookup('community.general.onepassword', %s, field=%s, vault=%s)", item, pythonQuote(ref.Label), vault)
+		case AnsibleLookupOpRead:
+			uri := ref.URI()
+			if d.VaultVar != "" {
+				uri = strings.Replace(uri, fmt.Sprintf("${%s}", d.VaultVar), "' ~ "+d.VaultVar+" ~ '", 1)
+			}
+			lookup = fmt.Sprintf("lookup('ansible.builtin.pipe', 'op read \"%s\"')", uri)
+		default:
+			return nil, fmt.Errorf("invalid lookup: %s, use %s or %s", d.Lookup, AnsibleLookupOnePassword, AnsibleLookupOpRead)
+		}
+		value, err := yamlQuote("{{ " + lookup + " }}")
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, templateVariable{Key: name, Value: value})
+	}
+
+	return renderTemplate(d.Path, "ansible", `{{template "header" .}}
+# The variables are looked up on the controller, which needs the op CLI signed in
+{{- if .Dest.VaultVar}} and the {{.Dest.VaultVar}} variable (e.g. {{.SecretReference.VaultName}}){{end}}.{{range .Vars}}
+{{.Key}}: {{.Value}}{{end}}
+`, &ansibleTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		Vars:            vars,
+	})
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestAnsibleVarsDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	secretReference := &op.SecretReference{
+		VaultName:   "prod",
+		VaultID:     "vault-id",
+		ItemName:    "my-app",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER", "tls.crt"},
+		FieldIDs:    []string{"DB_USER", "tls_crt-8d0fcdc3"},
+	}
+	header := `# This file was generated by optruck.
+#   - 1password vault: prod
+#   - 1password item: op://prod/my-app (op://vault-id/item-id)
+# The variables are looked up on the controller, which needs the op CLI signed in.
+`
+
+	testCases := []struct {
+		name     string
+		dest     AnsibleVarsDest
+		labels   []string
+		ids      []string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "onepassword lookup",
+			expected: header + `db_user: "{{ lookup('community.general.onepassword', 'item-id', field='DB_USER', vault='vault-id') }}"
+tls_crt: "{{ lookup('community.general.onepassword', 'item-id', field='tls.crt', vault='vault-id') }}"
+`,
+		},
+		{
+			name: "prefix and names",
+			dest: AnsibleVarsDest{VarPrefix: "my_app_", VarCase: AnsibleVarCaseKeep, RefOptions: op.RefOptions{RefStyle: op.RefStyleName}},
+			expected: header + `my_app_DB_USER: "{{ lookup('community.general.onepassword', 'my-app', field='DB_USER', vault='prod') }}"
+my_app_tls_crt: "{{ lookup('community.general.onepassword', 'my-app', field='tls.crt', vault='prod') }}"
+`,
+		},
+		{
+			name: "op read with vault variable",
+			dest: AnsibleVarsDest{Lookup: AnsibleLookupOpRead, RefOptions: op.RefOptions{VaultVar: "app_env"}},
+			expected: `# This file was generated by optruck.
+#   - 1password vault: ${app_env} (e.g. prod)
+#   - 1password item: op://${app_env}/my-app
+# The variables are looked up on the controller, which needs the op CLI signed in and the app_env variable (e.g. prod).
+db_user: "{{ lookup('ansible.builtin.pipe', 'op read \"op://' ~ app_env ~ '/my-app/DB_USER\"') }}"
+tls_crt: "{{ lookup('ansible.builtin.pipe', 'op read \"op://' ~ app_env ~ '/my-app/tls_crt-8d0fcdc3\"') }}"
+`,
+		},
+		{
+			name:    "names colliding after lowercasing",
+			labels:  []string{"TOKEN", "token"},
+			ids:     []string{"tokenid1", "tokenid2"},
+			wantErr: true,
+		},
+		{
+			name:    "name starting with a digit",
+			labels:  []string{"1KEY"},
+			ids:     []string{"1KEY"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := tc.dest
+			dest.Path = filepath.Join(tmpDir, DefaultAnsibleOutputPath)
+			ref := *secretReference
+			if tc.labels != nil {
+				ref.FieldLabels = tc.labels
+				ref.FieldIDs = tc.ids
+			}
+			err := writeDest(&dest, &ref)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Write() expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			assertFileContent(t, dest.Path, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+const DefaultAVPEnvOutputPath = "avp.env"
+
+// AVPSecretDest writes a Secret manifest of argocd-vault-plugin placeholders.
+type AVPSecretDest struct {
+	Path       string
+	Namespace  string
+	SecretName string
+	// RefOptions is left zero, as the item is always referenced by IDs.
+	op.RefOptions
+}
+
+func DefaultAVPSecretOutputPath(secretName string) string {
+	return fmt.Sprintf("%s-secret.avp.yaml", secretName)
+}
+
+func (d *AVPSecretDest) GetPath() string {
+	return d.Path
+}
+
+func (d *AVPSecretDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+// AVPEnvDest writes `KEY=<path:vaults/<id>/items/<id>#label>` lines, the
+// inline placeholders of argocd-vault-plugin, for env files consumed by a
+// generator (e.g. a Kustomize secretGenerator) in an Argo CD application.
+type AVPEnvDest struct {
+	Path string
+	// RefOptions is left zero, as the item is always referenced by IDs.
+	op.RefOptions
+}
+
+func (d *AVPEnvDest) GetPath() string {
+	return d.Path
+}
+
+func (d *AVPEnvDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+type avpTemplateData struct {
+	*op.SecretReference
+	Dest     any
+	ItemPath string
+}
+
+func (d *AVPSecretDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	itemPath, err := secretReference.GetItemPath(op.RefOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "avp-secret", `{{template "header" .}}
+# argocd-vault-plugin replaces the placeholders with the fields of the item. To apply by hand, run:
+#   $ argocd-vault-plugin generate {{.Dest.GetBasename}} | kubectl apply -f -
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{.Dest.SecretName}}
+  namespace: {{.Dest.Namespace}}
+  annotations:
+    avp.kubernetes.io/path: {{yaml .ItemPath}}
+type: Opaque
+stringData:{{range .SecretReference.FieldLabels}}
+  {{yaml .}}: <{{.}}>{{end}}
+`, &avpTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		ItemPath:        itemPath,
+	})
+}
+
+func (d *AVPEnvDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	itemPath, err := secretReference.GetItemPath(op.RefOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "avp-env", `{{template "header" .}}
+# argocd-vault-plugin replaces the placeholders with the fields of the item in the manifests
+# generated from this file, e.g. by a Kustomize secretGenerator.{{range .SecretReference.FieldLabels}}
+{{.}}=<path:{{$.ItemPath}}#{{.}}>{{end}}
+`, &avpTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		ItemPath:        itemPath,
+	})
+}
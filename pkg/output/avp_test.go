@@ -0,0 +1,61 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+var avpSecretReference = &op.SecretReference{
+	Account:     "test.1password.com",
+	VaultName:   "TestVault",
+	VaultID:     "vault-id",
+	ItemName:    "TestItem",
+	ItemID:      "item-id",
+	FieldLabels: []string{"DB_USER", "tls.crt"},
+	FieldIDs:    []string{"DB_USER", "tls_crt-8d0fcdc3"},
+}
+
+const avpHeader = `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+`
+
+func TestAVPSecretDestWrite(t *testing.T) {
+	dest := &AVPSecretDest{
+		Path:       filepath.Join(t.TempDir(), DefaultAVPSecretOutputPath("test-secret")),
+		Namespace:  "test-namespace",
+		SecretName: "test-secret",
+	}
+	if err := writeDest(dest, avpSecretReference); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	assertFileContent(t, dest.Path, avpHeader+`# argocd-vault-plugin replaces the placeholders with the fields of the item. To apply by hand, run:
+#   $ argocd-vault-plugin generate test-secret-secret.avp.yaml | kubectl apply -f -
+apiVersion: v1
+kind: Secret
+metadata:
+  name: test-secret
+  namespace: test-namespace
+  annotations:
+    avp.kubernetes.io/path: "vaults/vault-id/items/item-id"
+type: Opaque
+stringData:
+  "DB_USER": <DB_USER>
+  "tls.crt": <tls.crt>
+`)
+}
+
+func TestAVPEnvDestWrite(t *testing.T) {
+	dest := &AVPEnvDest{Path: filepath.Join(t.TempDir(), DefaultAVPEnvOutputPath)}
+	if err := writeDest(dest, avpSecretReference); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	assertFileContent(t, dest.Path, avpHeader+`# argocd-vault-plugin replaces the placeholders with the fields of the item in the manifests
+# generated from this file, e.g. by a Kustomize secretGenerator.
+DB_USER=<path:vaults/vault-id/items/item-id#DB_USER>
+tls.crt=<path:vaults/vault-id/items/item-id#tls.crt>
+`)
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+const (
+	DefaultGitHubActionsOutputPath = "load-secrets-step.yaml"
+	DefaultGitLabCIOutputPath      = "load-secrets.gitlab-ci.yml"
+)
+
+// GitHubActionsDest writes a workflow step for 1password/load-secrets-action.
+type GitHubActionsDest struct {
+	Path string
+	op.RefOptions
+}
+
+func (d *GitHubActionsDest) GetPath() string {
+	return d.Path
+}
+
+func (d *GitHubActionsDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+// GitLabCIDest writes a hidden GitLab CI job of references for `op run`.
+type GitLabCIDest struct {
+	Path string
+	op.RefOptions
+}
+
+func (d *GitLabCIDest) GetPath() string {
+	return d.Path
+}
+
+func (d *GitLabCIDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+type ciTemplateData struct {
+	*op.SecretReference
+	Dest any
+	// JobName is the name of the hidden GitLab CI job
+	JobName string
+	// Expressions are written as is, since their "{{" would be parsed as
+	// actions of the template.
+	TokenExpr string
+	Variables []templateVariable
+}
+
+// templateVariable is a key and its already quoted value.
+type templateVariable struct {
+	Key   string
+	Value string
+}
+
+func templateVariables(refs []op.FieldRef, vaultVar, vaultExpr string) ([]templateVariable, error) {
+	if err := validateEnvNames(refs); err != nil {
+		return nil, err
+	}
+	vars := make([]templateVariable, 0, len(refs))
+	for _, ref := range refs {
+		uri := ref.URI()
+		if vaultVar != "" {
+			uri = strings.Replace(uri, fmt.Sprintf("${%s}", vaultVar), vaultExpr, 1)
+		}
+		value, err := yamlQuote(uri)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, templateVariable{Key: ref.Label, Value: value})
+	}
+	return vars, nil
+}
+
+func (d *GitHubActionsDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := templateVariables(refs, d.VaultVar, fmt.Sprintf("${{ vars.%s }}", d.VaultVar))
+	if err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "github-actions", `{{template "header" .}}
+# Add the step to a job of your workflow. It needs the OP_SERVICE_ACCOUNT_TOKEN secret
+{{- if .Dest.VaultVar}} and the {{.Dest.VaultVar}} variable (e.g. {{.SecretReference.VaultName}}){{end}}.
+- name: Load secrets from 1Password
+  uses: 1password/load-secrets-action@v2
+  with:
+    export-env: true
+  env:
+    OP_SERVICE_ACCOUNT_TOKEN: {{.TokenExpr}}{{range .Variables}}
+    {{.Key}}: {{.Value}}{{end}}
+`, &ciTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		TokenExpr:       "${{ secrets.OP_SERVICE_ACCOUNT_TOKEN }}",
+		Variables:       vars,
+	})
+}
+
+func (d *GitLabCIDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+	vars, err := templateVariables(refs, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "gitlab-ci", `{{template "header" .}}
+# Include the file in .gitlab-ci.yml and extend the job. It needs the op CLI and the
+# OP_SERVICE_ACCOUNT_TOKEN variable{{if .Dest.VaultVar}}, and the {{.Dest.VaultVar}} variable (e.g. {{.SecretReference.VaultName}}){{end}}:
+#   deploy:
+#     extends: {{.JobName}}
+#     script:
+#       - op run -- ./deploy.sh
+{{.JobName}}:
+  variables:{{range .Variables}}
+    {{.Key}}: {{.Value}}{{end}}
+`, &ciTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		JobName:         ".onepassword-" + strings.ReplaceAll(TerraformName(secretReference.ItemName), "_", "-"),
+		Variables:       vars,
+	})
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+var ciSecretReference = &op.SecretReference{
+	VaultName:   "prod",
+	VaultID:     "vault-id",
+	ItemName:    "my-app",
+	ItemID:      "item-id",
+	FieldLabels: []string{"DB_USER", "DB_PASS"},
+}
+
+func TestGitHubActionsDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testCases := []struct {
+		name       string
+		refOptions op.RefOptions
+		expected   string
+	}{
+		{
+			name: "item ID",
+			expected: `# This file was generated by optruck.
+#   - 1password vault: prod
+#   - 1password item: op://prod/my-app (op://vault-id/item-id)
+# Add the step to a job of your workflow. It needs the OP_SERVICE_ACCOUNT_TOKEN secret.
+- name: Load secrets from 1Password
+  uses: 1password/load-secrets-action@v2
+  with:
+    export-env: true
+  env:
+    OP_SERVICE_ACCOUNT_TOKEN: ${{ secrets.OP_SERVICE_ACCOUNT_TOKEN }}
+    DB_USER: "op://vault-id/item-id/DB_USER"
+    DB_PASS: "op://vault-id/item-id/DB_PASS"
+`,
+		},
+		{
+			name:       "vault variable",
+			refOptions: op.RefOptions{VaultVar: "APP_ENV"},
+			expected: `# This file was generated by optruck.
+#   - 1password vault: ${APP_ENV} (e.g. prod)
+#   - 1password item: op://${APP_ENV}/my-app
+# Add the step to a job of your workflow. It needs the OP_SERVICE_ACCOUNT_TOKEN secret and the APP_ENV variable (e.g. prod).
+- name: Load secrets from 1Password
+  uses: 1password/load-secrets-action@v2
+  with:
+    export-env: true
+  env:
+    OP_SERVICE_ACCOUNT_TOKEN: ${{ secrets.OP_SERVICE_ACCOUNT_TOKEN }}
+    DB_USER: "op://${{ vars.APP_ENV }}/my-app/DB_USER"
+    DB_PASS: "op://${{ vars.APP_ENV }}/my-app/DB_PASS"
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := &GitHubActionsDest{
+				Path:       filepath.Join(tmpDir, DefaultGitHubActionsOutputPath),
+				RefOptions: tc.refOptions,
+			}
+			if err := writeDest(dest, ciSecretReference); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			assertFileContent(t, dest.Path, tc.expected)
+		})
+	}
+}
+
+func TestGitLabCIDestWrite(t *testing.T) {
+	dest := &GitLabCIDest{
+		Path:       filepath.Join(t.TempDir(), DefaultGitLabCIOutputPath),
+		RefOptions: op.RefOptions{VaultVar: "APP_ENV"},
+	}
+	if err := writeDest(dest, ciSecretReference); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	assertFileContent(t, dest.Path, `# This file was generated by optruck.
+#   - 1password vault: ${APP_ENV} (e.g. prod)
+#   - 1password item: op://${APP_ENV}/my-app
+# Include the file in .gitlab-ci.yml and extend the job. It needs the op CLI and the
+# OP_SERVICE_ACCOUNT_TOKEN variable, and the APP_ENV variable (e.g. prod):
+#   deploy:
+#     extends: .onepassword-my-app
+#     script:
+#       - op run -- ./deploy.sh
+.onepassword-my-app:
+  variables:
+    DB_USER: "op://${APP_ENV}/my-app/DB_USER"
+    DB_PASS: "op://${APP_ENV}/my-app/DB_PASS"
+`)
+}
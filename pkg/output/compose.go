@@ -0,0 +1,106 @@
+package output
+
+import (
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// ComposeDest writes a docker compose override file for a service.
+//
+// By default the override sets the service's environment to the references,
+// and is restored with `op inject`. If EnvFile is set, the override only
+// points env_file at it, and the template for the env file is written next
+// to it as "<EnvFile>.1password".
+type ComposeDest struct {
+	Path    string
+	Service string
+	EnvFile string
+	op.RefOptions
+}
+
+func (d *ComposeDest) GetPath() string {
+	return d.Path
+}
+
+func (d *ComposeDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+// RestoredBasename is the name of the override file `op inject` writes.
+func (d *ComposeDest) RestoredBasename() string {
+	return restoredBasename(d.Path, "compose.override.yaml")
+}
+
+// EnvTemplatePath is the path of the env file template. Like the restore
+// commands, EnvFile is relative to the directory of the override file.
+func (d *ComposeDest) EnvTemplatePath() string {
+	return envTemplatePath(d.Path, d.EnvFile)
+}
+
+// DefaultComposeOutputPath returns the default path of the override file,
+// which only needs `op inject` when it holds the references.
+func DefaultComposeOutputPath(envFile string) string {
+	if envFile != "" {
+		return "compose.override.yaml"
+	}
+	return "compose.override.yaml.1password"
+}
+
+// CheckValues rejects a `$`, which docker compose would interpolate, and the
+// values the file holding them cannot take as is.
+func (d *ComposeDest) CheckValues(secrets map[string]string) error {
+	if err := checkValueChars(secrets, func(r rune) bool { return r == '$' }, "docker compose would interpolate"); err != nil {
+		return err
+	}
+	if d.EnvFile != "" {
+		return checkValueChars(secrets, func(r rune) bool { return r == '\n' || r == '\r' }, "would end the line in the env file")
+	}
+	return checkValueChars(secrets, breaksDoubleQuotedYAML, "cannot be put as is in the double-quoted values of the compose override")
+}
+
+type composeTemplateData struct {
+	*op.SecretReference
+	Dest      *ComposeDest
+	FieldRefs []op.FieldRef
+}
+
+func (d *ComposeDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+	data := &composeTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		FieldRefs:       refs,
+	}
+
+	if d.EnvFile == "" {
+		return renderTemplate(d.Path, "compose", `{{template "header" .}}
+# To restore, run the following command:
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o {{.Dest.RestoredBasename}}
+services:
+  {{yaml .Dest.Service}}:
+    environment:{{range .FieldRefs}}
+      {{yaml .Label}}: {{yaml .Ref}}{{end}}
+`, data)
+	}
+
+	envFile, err := renderTemplate(d.EnvTemplatePath(), "compose-env-file", envFileTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	override, err := renderTemplate(d.Path, "compose-override", `{{template "header" .}}
+# The secrets are read from {{.Dest.EnvFile}}. Before starting the service, run the following command:
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.EnvFile}}.1password {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o {{.Dest.EnvFile}}
+services:
+  {{yaml .Dest.Service}}:
+    env_file:
+      - {{yaml .Dest.EnvFile}}
+`, data)
+	if err != nil {
+		return nil, err
+	}
+	return append(envFile, override...), nil
+}
@@ -0,0 +1,140 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestComposeDestWrite(t *testing.T) {
+	secretReference := &op.SecretReference{
+		Account:     "test.1password.com",
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER", "DB_PASS"},
+	}
+	header := `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+`
+
+	t.Run("environment", func(t *testing.T) {
+		dir := t.TempDir()
+		dest := &ComposeDest{
+			Path:    filepath.Join(dir, DefaultComposeOutputPath("")),
+			Service: "app",
+		}
+		if err := writeDest(dest, secretReference); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		assertFileContent(t, dest.Path, header+`# To restore, run the following command:
+#   $ op inject -i compose.override.yaml.1password --account test.1password.com -o compose.override.yaml
+services:
+  "app":
+    environment:
+      "DB_USER": "{{op://vault-id/item-id/DB_USER}}"
+      "DB_PASS": "{{op://vault-id/item-id/DB_PASS}}"
+`)
+	})
+
+	t.Run("env_file", func(t *testing.T) {
+		dir := t.TempDir()
+		dest := &ComposeDest{
+			Path:    filepath.Join(dir, DefaultComposeOutputPath(".env.app")),
+			Service: "app",
+			EnvFile: ".env.app",
+		}
+		if err := writeDest(dest, secretReference); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+
+		assertFileContent(t, dest.Path, header+`# The secrets are read from .env.app. Before starting the service, run the following command:
+#   $ op inject -i .env.app.1password --account test.1password.com -o .env.app
+services:
+  "app":
+    env_file:
+      - ".env.app"
+`)
+		assertFileContent(t, filepath.Join(dir, ".env.app.1password"), header+`# To restore, run the following command:
+#   $ op inject -i .env.app.1password --account test.1password.com -o .env.app
+DB_USER={{op://vault-id/item-id/DB_USER}}
+DB_PASS={{op://vault-id/item-id/DB_PASS}}
+`)
+	})
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read generated file: %v", err)
+	}
+	if got := string(content); got != want {
+		t.Errorf("generated content of %s mismatch\nwant:\n%s\ngot:\n%s", filepath.Base(path), want, got)
+	}
+}
+
+func TestComposeDestCheckValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		envFile string
+		secrets map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "plain values",
+			secrets: map[string]string{"A": "p@ss word!#'", "B": "日本語"},
+		},
+		{
+			name:    "dollar",
+			secrets: map[string]string{"A": "pa$word"},
+			wantErr: true,
+		},
+		{
+			name:    "dollar in the env file",
+			envFile: ".env.app",
+			secrets: map[string]string{"A": "pa$$word"},
+			wantErr: true,
+		},
+		{
+			name:    "double quote",
+			secrets: map[string]string{"A": `say "hi"`},
+			wantErr: true,
+		},
+		{
+			name:    "backslash",
+			secrets: map[string]string{"A": `C:\path`},
+			wantErr: true,
+		},
+		{
+			name:    "line break",
+			secrets: map[string]string{"A": "line1\nline2"},
+			wantErr: true,
+		},
+		{
+			name:    "quotes in the env file",
+			envFile: ".env.app",
+			secrets: map[string]string{"A": `say "hi" \o/`},
+		},
+		{
+			name:    "line break in the env file",
+			envFile: ".env.app",
+			secrets: map[string]string{"A": "line1\nline2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&ComposeDest{EnvFile: tt.envFile}).CheckValues(tt.secrets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
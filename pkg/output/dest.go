@@ -2,11 +2,43 @@ package output
 
 import "github.com/yammerjp/optruck/pkg/op"
 
+// Dest renders the templates of a secret reference. The files are written by
+// Write, so that every dest replaces existing files the same way. Most hold
+// references restored by `op inject`; the others, e.g. the manifests read by
+// an operator, hold no secrets and are used as written.
 type Dest interface {
-	Write(resp *op.SecretReference) error
+	Render(resp *op.SecretReference) ([]File, error)
 	GetPath() string
 	GetBasename() string
+	GetRefOptions() op.RefOptions
+}
+
+// ValueChecker is a Dest whose restored file only holds some values, e.g.
+// because it quotes them. CheckValues fails for the secrets that would break
+// the restored file once `op inject` puts them in.
+type ValueChecker interface {
+	CheckValues(secrets map[string]string) error
 }
 
 var _ Dest = (*EnvTemplateDest)(nil)
 var _ Dest = (*K8sSecretTemplateDest)(nil)
+var _ Dest = (*UserTemplateDest)(nil)
+var _ Dest = (*OpRunEnvDest)(nil)
+var _ Dest = (*ComposeDest)(nil)
+var _ Dest = (*ShellDest)(nil)
+var _ Dest = (*SystemdDest)(nil)
+var _ Dest = (*OnePasswordItemDest)(nil)
+var _ Dest = (*ExternalSecretDest)(nil)
+var _ Dest = (*HelmValuesDest)(nil)
+var _ Dest = (*KustomizeDest)(nil)
+var _ Dest = (*TerraformDest)(nil)
+var _ Dest = (*GitHubActionsDest)(nil)
+var _ Dest = (*GitLabCIDest)(nil)
+var _ Dest = (*AnsibleVarsDest)(nil)
+var _ Dest = (*AVPSecretDest)(nil)
+var _ Dest = (*AVPEnvDest)(nil)
+
+var _ ValueChecker = (*ShellDest)(nil)
+var _ ValueChecker = (*ComposeDest)(nil)
+var _ ValueChecker = (*HelmValuesDest)(nil)
+var _ ValueChecker = (*SystemdDest)(nil)
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around a change, as in
+// `diff -u`.
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns the changes from before to after of the file at path
+// in the unified format.
+func unifiedDiff(path string, before, after []byte) string {
+	lines := diffLines(splitLines(string(before)), splitLines(string(after)))
+
+	// the number of old and new lines before each line of the diff
+	oldBefore := make([]int, len(lines)+1)
+	newBefore := make([]int, len(lines)+1)
+	for i, line := range lines {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		if line.op != '+' {
+			oldBefore[i+1]++
+		}
+		if line.op != '-' {
+			newBefore[i+1]++
+		}
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "--- %s\n+++ %s (new)\n", path, path)
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].op == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		for {
+			for end < len(lines) && lines[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			// hunks closer than twice the context are merged
+			if next < len(lines) && next-end <= 2*diffContext {
+				end = next
+				continue
+			}
+			end = min(end+diffContext, len(lines))
+			break
+		}
+
+		fmt.Fprintf(b, "@@ -%s +%s @@\n",
+			hunkRange(oldBefore[start], oldBefore[end]-oldBefore[start]),
+			hunkRange(newBefore[start], newBefore[end]-newBefore[start]))
+		for _, line := range lines[start:end] {
+			fmt.Fprintf(b, "%c%s\n", line.op, line.text)
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange formats the range of a hunk, which starts after the line before
+// it when it is empty.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines aligns a and b along their longest common subsequence. The
+// templates are short, so the quadratic table is fine.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+package output
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "changed line",
+			before: "# header\nA=1\nB=2\nC=3\n",
+			after:  "# header\nA=1\nB=20\nC=3\n",
+			want: `--- x.env
++++ x.env (new)
+@@ -1,4 +1,4 @@
+ # header
+ A=1
+-B=2
++B=20
+ C=3
+`,
+		},
+		{
+			name:   "distant changes in separate hunks",
+			before: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			after:  "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			want: `--- x.env
++++ x.env (new)
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -7,4 +8,3 @@
+ 7
+ 8
+ 9
+-10
+`,
+		},
+		{
+			name:   "close changes in one hunk",
+			before: "1\n2\n3\n4\n5\n6\n7\n",
+			after:  "1\nX\n3\n4\n5\n6\nY\n",
+			want: `--- x.env
++++ x.env (new)
+@@ -1,7 +1,7 @@
+ 1
+-2
++X
+ 3
+ 4
+ 5
+ 6
+-7
++Y
+`,
+		},
+		{
+			name:  "empty before",
+			after: "A=1\n",
+			want: `--- x.env
++++ x.env (new)
+@@ -0,0 +1 @@
++A=1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("x.env", []byte(tt.before), []byte(tt.after)); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,14 @@
 package output
 
 import (
-	"os"
 	"path/filepath"
-	"text/template"
 
 	"github.com/yammerjp/optruck/pkg/op"
 )
 
 type EnvTemplateDest struct {
 	Path string
+	op.RefOptions
 }
 
 func (d *EnvTemplateDest) GetPath() string {
@@ -22,30 +21,23 @@ func (d *EnvTemplateDest) GetBasename() string {
 
 type envTemplateData struct {
 	*op.SecretReference
-	Dest *EnvTemplateDest
+	Dest      *EnvTemplateDest
+	FieldRefs []op.FieldRef
 }
 
-func (d *EnvTemplateDest) Write(secretReference *op.SecretReference) error {
-	file, err := os.Create(d.Path)
+func (d *EnvTemplateDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
 
-	tmpl, err := template.New("env-template").Parse(`# This file was generated by optruck.{{if .SecretReference.Account}}
-#   - 1password account: {{.SecretReference.Account}}{{end}}{{if .SecretReference.VaultName}}
-#   - 1password vault: {{.SecretReference.VaultName}}{{end}}
+	return renderTemplate(d.Path, "env-template", `{{template "header" .}}
 # To restore, run the following command:
-#   $ op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o .env{{range .GetFieldRefs}}
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o .env{{range .FieldRefs}}
 {{.Label}}={{.Ref}}{{end}}
-`)
-	if err != nil {
-		return err
-	}
-
-	data := &envTemplateData{
+`, &envTemplateData{
 		SecretReference: secretReference,
 		Dest:            d,
-	}
-	return tmpl.Execute(file, data)
+		FieldRefs:       refs,
+	})
 }
@@ -38,6 +38,7 @@ func TestEnvTemplateDestWrite(t *testing.T) {
 			expected: `# This file was generated by optruck.
 #   - 1password account: test.1password.com
 #   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
 # To restore, run the following command:
 #   $ op inject -i test1.env --account test.1password.com -o .env
 DB_USER={{op://vault-id/item-id/DB_USER}}
@@ -58,9 +59,54 @@ DB_PASS={{op://vault-id/item-id/DB_PASS}}
 			},
 			expected: `# This file was generated by optruck.
 #   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
 # To restore, run the following command:
 #   $ op inject -i test2.env -o .env
 API_KEY={{op://vault-id/item-id/API_KEY}}
+`,
+		},
+		{
+			name: "name reference style",
+			dest: &EnvTemplateDest{
+				Path:       filepath.Join(tmpDir, "test3.env"),
+				RefOptions: op.RefOptions{RefStyle: op.RefStyleName},
+			},
+			secretReference: &op.SecretReference{
+				VaultName:   "Development",
+				VaultID:     "vault-id",
+				ItemName:    "my-app",
+				ItemID:      "item-id",
+				FieldLabels: []string{"DB_PASSWORD"},
+			},
+			expected: `# This file was generated by optruck.
+#   - 1password vault: Development
+#   - 1password item: op://Development/my-app (op://vault-id/item-id)
+# To restore, run the following command:
+#   $ op inject -i test3.env -o .env
+DB_PASSWORD={{op://Development/my-app/DB_PASSWORD}}
+`,
+		},
+		{
+			name: "vault variable",
+			dest: &EnvTemplateDest{
+				Path:       filepath.Join(tmpDir, "test4.env"),
+				RefOptions: op.RefOptions{VaultVar: "APP_ENV"},
+			},
+			secretReference: &op.SecretReference{
+				Account:     "test.1password.com",
+				VaultName:   "dev",
+				VaultID:     "vault-id",
+				ItemName:    "my-app",
+				ItemID:      "item-id",
+				FieldLabels: []string{"DB_PASSWORD"},
+			},
+			expected: `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: ${APP_ENV} (e.g. dev)
+#   - 1password item: op://${APP_ENV}/my-app
+# To restore, run the following command:
+#   $ APP_ENV=dev op inject -i test4.env --account test.1password.com -o .env
+DB_PASSWORD={{op://${APP_ENV}/my-app/DB_PASSWORD}}
 `,
 		},
 	}
@@ -68,7 +114,7 @@ API_KEY={{op://vault-id/item-id/API_KEY}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Write the template
-			err := tc.dest.Write(tc.secretReference)
+			err := writeDest(tc.dest, tc.secretReference)
 			if err != nil {
 				t.Fatalf("Write() error = %v", err)
 			}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+const (
+	DefaultExternalSecretStoreName       = "onepassword"
+	DefaultExternalSecretStoreKind       = "SecretStore"
+	DefaultExternalSecretRefreshInterval = "1h"
+)
+
+// ExternalSecretDest writes an ExternalSecret for the External Secrets
+// Operator, which syncs the Secret from a 1Password SecretStore. The store
+// selects the vault and looks up the item by its title, so each field is
+// mapped to a remoteRef of the item name and the field label.
+type ExternalSecretDest struct {
+	Path            string
+	Namespace       string
+	SecretName      string
+	StoreName       string
+	StoreKind       string
+	RefreshInterval string
+	// RefOptions only carries the vault variable of a manifest shared by
+	// several vaults: the manifest holds no references, as the vault is set
+	// in the SecretStore and the item is looked up by its title.
+	op.RefOptions
+}
+
+// GetRefOptions references the item by name whatever the ref style, so that
+// its title is checked to be unique, as the SecretStore looks it up by title.
+func (d *ExternalSecretDest) GetRefOptions() op.RefOptions {
+	return op.RefOptions{RefStyle: op.RefStyleName, VaultVar: d.VaultVar}
+}
+
+func DefaultExternalSecretOutputPath(secretName string) string {
+	return fmt.Sprintf("%s-externalsecret.yaml", secretName)
+}
+
+func (d *ExternalSecretDest) GetPath() string {
+	return d.Path
+}
+
+func (d *ExternalSecretDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+type externalSecretTemplateData struct {
+	*op.SecretReference
+	Dest *ExternalSecretDest
+}
+
+func (d *ExternalSecretDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	return renderTemplate(d.Path, "external-secret", `{{template "header" .}}
+# The External Secrets Operator creates the Secret {{.Dest.Namespace}}/{{.Dest.SecretName}} from the item,
+# using the {{.Dest.StoreKind}} {{.Dest.StoreName}} configured for the vault {{if .Dest.VaultVar}}${{"{"}}{{.Dest.VaultVar}}{{"}"}}{{else}}{{.SecretReference.VaultName}}{{end}}.
+# To apply, run the following command:
+#   $ kubectl apply -f {{.Dest.GetBasename}}
+apiVersion: external-secrets.io/v1
+kind: ExternalSecret
+metadata:
+  name: {{.Dest.SecretName}}
+  namespace: {{.Dest.Namespace}}
+spec:
+  refreshInterval: {{yaml .Dest.RefreshInterval}}
+  secretStoreRef:
+    name: {{yaml .Dest.StoreName}}
+    kind: {{.Dest.StoreKind}}
+  target:
+    name: {{.Dest.SecretName}}
+    creationPolicy: Owner
+  data:{{range .SecretReference.FieldLabels}}
+    - secretKey: {{yaml .}}
+      remoteRef:
+        key: {{yaml $.SecretReference.ItemName}}
+        property: {{yaml .}}{{end}}
+`, &externalSecretTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+	})
+}
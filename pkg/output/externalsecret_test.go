@@ -0,0 +1,119 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestExternalSecretDestWrite(t *testing.T) {
+	dest := &ExternalSecretDest{
+		Path:            filepath.Join(t.TempDir(), DefaultExternalSecretOutputPath("test-secret")),
+		Namespace:       "test-namespace",
+		SecretName:      "test-secret",
+		StoreName:       "onepassword-dev",
+		StoreKind:       "ClusterSecretStore",
+		RefreshInterval: "15m",
+	}
+	err := writeDest(dest, &op.SecretReference{
+		Account:     "test.1password.com",
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER", "tls.crt"},
+		FieldIDs:    []string{"DB_USER", "tls_crt-8d0fcdc3"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	assertFileContent(t, dest.Path, `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+# The External Secrets Operator creates the Secret test-namespace/test-secret from the item,
+# using the ClusterSecretStore onepassword-dev configured for the vault TestVault.
+# To apply, run the following command:
+#   $ kubectl apply -f test-secret-externalsecret.yaml
+apiVersion: external-secrets.io/v1
+kind: ExternalSecret
+metadata:
+  name: test-secret
+  namespace: test-namespace
+spec:
+  refreshInterval: "15m"
+  secretStoreRef:
+    name: "onepassword-dev"
+    kind: ClusterSecretStore
+  target:
+    name: test-secret
+    creationPolicy: Owner
+  data:
+    - secretKey: "DB_USER"
+      remoteRef:
+        key: "TestItem"
+        property: "DB_USER"
+    - secretKey: "tls.crt"
+      remoteRef:
+        key: "TestItem"
+        property: "tls.crt"
+`)
+}
+
+func TestExternalSecretDestWriteWithVaultVar(t *testing.T) {
+	dest := &ExternalSecretDest{
+		Path:            filepath.Join(t.TempDir(), DefaultExternalSecretOutputPath("test-secret")),
+		Namespace:       "test-namespace",
+		SecretName:      "test-secret",
+		StoreName:       DefaultExternalSecretStoreName,
+		StoreKind:       DefaultExternalSecretStoreKind,
+		RefreshInterval: DefaultExternalSecretRefreshInterval,
+		RefOptions:      op.RefOptions{VaultVar: "APP_ENV"},
+	}
+	err := writeDest(dest, &op.SecretReference{
+		VaultName:   "dev",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER"},
+		FieldIDs:    []string{"DB_USER"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	assertFileContent(t, dest.Path, `# This file was generated by optruck.
+#   - 1password vault: ${APP_ENV} (e.g. dev)
+#   - 1password item: op://${APP_ENV}/TestItem
+# The External Secrets Operator creates the Secret test-namespace/test-secret from the item,
+# using the SecretStore onepassword configured for the vault ${APP_ENV}.
+# To apply, run the following command:
+#   $ kubectl apply -f test-secret-externalsecret.yaml
+apiVersion: external-secrets.io/v1
+kind: ExternalSecret
+metadata:
+  name: test-secret
+  namespace: test-namespace
+spec:
+  refreshInterval: "1h"
+  secretStoreRef:
+    name: "onepassword"
+    kind: SecretStore
+  target:
+    name: test-secret
+    creationPolicy: Owner
+  data:
+    - secretKey: "DB_USER"
+      remoteRef:
+        key: "TestItem"
+        property: "DB_USER"
+`)
+}
+
+func TestExternalSecretDestGetRefOptions(t *testing.T) {
+	dest := &ExternalSecretDest{RefOptions: op.RefOptions{RefStyle: op.RefStyleID, VaultVar: "APP_ENV"}}
+	want := op.RefOptions{RefStyle: op.RefStyleName, VaultVar: "APP_ENV"}
+	if got := dest.GetRefOptions(); got != want {
+		t.Errorf("GetRefOptions() = %+v, want %+v", got, want)
+	}
+}
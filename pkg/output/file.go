@@ -0,0 +1,148 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// File is a rendered template and the path to write it to.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// StdoutPath is the path that writes a template to stdout instead of a file.
+const StdoutPath = "-"
+
+// stdout is where the templates for StdoutPath go, replaced in tests.
+var stdout io.Writer = os.Stdout
+
+// FileMode is the mode of a new template file. The templates only hold
+// references to the secrets, so they are readable like the rest of a
+// repository. A replaced file keeps its mode.
+const FileMode os.FileMode = 0o644
+
+// ErrTemplateChanged is returned when a template would replace an existing
+// file with different content, unless WriteOptions.Force is set.
+var ErrTemplateChanged = errors.New("the existing file differs from the new template")
+
+type WriteOptions struct {
+	// Force replaces the existing files that differ from the templates.
+	Force bool
+	// Diff receives a unified diff for each existing file that differs.
+	Diff io.Writer
+}
+
+// Write renders the dest and writes its files.
+func Write(dest Dest, resp *op.SecretReference, opts WriteOptions) error {
+	files, err := dest.Render(resp)
+	if err != nil {
+		return err
+	}
+	return WriteFiles(files, opts)
+}
+
+// WriteFiles writes each file to a temporary file and renames it over the
+// path, so that the path holds either the old or the new content. All the
+// existing files are compared first, and none is written if any differs
+// without opts.Force. Files with the same content are left untouched.
+func WriteFiles(files []File, opts WriteOptions) error {
+	pending, err := compareFiles(files, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range pending {
+		if file.Path == StdoutPath {
+			if _, err := stdout.Write(file.Content); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeFileAtomic(file.Path, file.Content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// CheckFiles compares the files with the existing ones like WriteFiles, and
+// fails the same way, without writing any of them.
+func CheckFiles(files []File, opts WriteOptions) error {
+	_, err := compareFiles(files, opts)
+	return err
+}
+
+// compareFiles returns the files that differ from the existing ones, or
+// ErrTemplateChanged if any existing one differs without opts.Force.
+func compareFiles(files []File, opts WriteOptions) ([]File, error) {
+	pending := make([]File, 0, len(files))
+	changed := []string{}
+	for _, file := range files {
+		if file.Path == StdoutPath {
+			pending = append(pending, file)
+			continue
+		}
+		old, err := os.ReadFile(file.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			pending = append(pending, file)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(old, file.Content) {
+			slog.Debug("template unchanged", "path", file.Path)
+			continue
+		}
+		if opts.Diff != nil {
+			fmt.Fprint(opts.Diff, unifiedDiff(file.Path, old, file.Content))
+		}
+		changed = append(changed, file.Path)
+		pending = append(pending, file)
+	}
+	if len(changed) > 0 && !opts.Force {
+		return nil, fmt.Errorf("%w: %s. Please check the diff and use --force to replace", ErrTemplateChanged, strings.Join(changed, ", "))
+	}
+	return pending, nil
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	mode := FileMode
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// a no-op once the file is renamed
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
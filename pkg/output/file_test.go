@@ -0,0 +1,183 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// writeDest writes the files of a dest, replacing the ones of the previous
+// test cases.
+func writeDest(dest Dest, resp *op.SecretReference) error {
+	return Write(dest, resp, WriteOptions{Force: true})
+}
+
+func TestWriteTemplateToStdout(t *testing.T) {
+	var buf bytes.Buffer
+	stdout = &buf
+	t.Cleanup(func() { stdout = os.Stdout })
+
+	dest := &OpRunEnvDest{Path: StdoutPath}
+	err := writeDest(dest, &op.SecretReference{
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"API_KEY"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := `# This file was generated by optruck.
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+# To run a command with the secrets, run the following command:
+#   $ op run --env-file - -- <command>
+API_KEY=op://vault-id/item-id/API_KEY
+`
+	if got := buf.String(); got != want {
+		t.Errorf("Write() stdout mismatch\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	const current = "A=1\nB=2\n"
+	const next = "A=1\nB=3\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		mode     os.FileMode
+		content  string
+		opts     WriteOptions
+		wantErr  error
+		want     string
+		wantMode os.FileMode
+		wantDiff bool
+	}{
+		{
+			name:     "new file",
+			content:  next,
+			want:     next,
+			wantMode: FileMode,
+		},
+		{
+			name:     "same content",
+			existing: current,
+			mode:     0o600,
+			content:  current,
+			want:     current,
+			wantMode: 0o600,
+		},
+		{
+			name:     "different content",
+			existing: current,
+			mode:     0o600,
+			content:  next,
+			wantErr:  ErrTemplateChanged,
+			want:     current,
+			wantMode: 0o600,
+			wantDiff: true,
+		},
+		{
+			name:     "different content with force keeps the mode",
+			existing: current,
+			mode:     0o600,
+			content:  next,
+			opts:     WriteOptions{Force: true},
+			want:     next,
+			wantMode: 0o600,
+			wantDiff: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, ".env.1password")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), tt.mode); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var diff bytes.Buffer
+			tt.opts.Diff = &diff
+
+			err := WriteFiles([]File{{Path: path, Content: []byte(tt.content)}}, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteFiles() error = %v, want %v", err, tt.wantErr)
+			}
+
+			assertFileContent(t, path, tt.want)
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != tt.wantMode {
+				t.Errorf("mode = %v, want %v", got, tt.wantMode)
+			}
+			if got := diff.Len() > 0; got != tt.wantDiff {
+				t.Errorf("diff written = %v, want %v:\n%s", got, tt.wantDiff, diff.String())
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("temporary files left in %s: %v", dir, entries)
+			}
+		})
+	}
+}
+
+func TestWriteFilesChecksAllFilesFirst(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "kustomization.yaml")
+	if err := os.WriteFile(changed, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	added := filepath.Join(dir, "app.env.1password")
+
+	err := WriteFiles([]File{
+		{Path: added, Content: []byte("A={{op://v/i/A}}\n")},
+		{Path: changed, Content: []byte("new\n")},
+	}, WriteOptions{})
+	if !errors.Is(err, ErrTemplateChanged) || !strings.Contains(err.Error(), changed) {
+		t.Fatalf("WriteFiles() error = %v, want %v for %s", err, ErrTemplateChanged, changed)
+	}
+	if _, err := os.Stat(added); !os.IsNotExist(err) {
+		t.Errorf("%s was written although %s differs", added, changed)
+	}
+}
+
+func TestCheckFiles(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, ".env.1password")
+	if err := os.WriteFile(changed, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	added := filepath.Join(dir, "app.env.1password")
+	files := []File{
+		{Path: added, Content: []byte("A={{op://v/i/A}}\n")},
+		{Path: changed, Content: []byte("new\n")},
+	}
+
+	if err := CheckFiles(files, WriteOptions{}); !errors.Is(err, ErrTemplateChanged) {
+		t.Errorf("CheckFiles() error = %v, want %v", err, ErrTemplateChanged)
+	}
+	if err := CheckFiles(files, WriteOptions{Force: true}); err != nil {
+		t.Errorf("CheckFiles() with force error = %v", err)
+	}
+	if _, err := os.Stat(added); !os.IsNotExist(err) {
+		t.Errorf("%s was written by CheckFiles", added)
+	}
+	if got, _ := os.ReadFile(changed); string(got) != "old\n" {
+		t.Errorf("%s = %q, want it untouched", changed, got)
+	}
+}
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+const DefaultHelmValuesOutputPath = "secret-values.yaml.1password"
+
+// HelmValuesDest writes a Helm values file with the references nested under
+// KeyPath (e.g. "app.secrets"), to be restored by `op inject` before
+// rendering the chart with `-f`.
+type HelmValuesDest struct {
+	Path    string
+	KeyPath string
+	op.RefOptions
+}
+
+func (d *HelmValuesDest) GetPath() string {
+	return d.Path
+}
+
+func (d *HelmValuesDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+func (d *HelmValuesDest) RestoredBasename() string {
+	return restoredBasename(d.Path, "secret-values.yaml")
+}
+
+// ParseHelmKeyPath splits a dot-separated key path, e.g. "app.secrets".
+func ParseHelmKeyPath(keyPath string) ([]string, error) {
+	if keyPath == "" {
+		return nil, nil
+	}
+	keys := strings.Split(keyPath, ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("invalid key path: %s, use dot-separated keys like 'app.secrets'", keyPath)
+		}
+	}
+	return keys, nil
+}
+
+func (d *HelmValuesDest) CheckValues(secrets map[string]string) error {
+	return checkValueChars(secrets, breaksDoubleQuotedYAML, "cannot be put as is in the double-quoted values of the Helm values file")
+}
+
+type helmValuesTemplateData struct {
+	*op.SecretReference
+	Dest      *HelmValuesDest
+	FieldRefs []op.FieldRef
+	Keys      []helmValuesKey
+	Indent    string
+}
+
+type helmValuesKey struct {
+	Indent string
+	Key    string
+}
+
+func (d *HelmValuesDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+	keyPath, err := ParseHelmKeyPath(d.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]helmValuesKey, 0, len(keyPath))
+	for i, key := range keyPath {
+		keys = append(keys, helmValuesKey{Indent: strings.Repeat("  ", i), Key: key})
+	}
+
+	return renderTemplate(d.Path, "helm-values", `{{template "header" .}}
+# To restore, run the following command and pass the values with "-f {{.Dest.RestoredBasename}}":
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o {{.Dest.RestoredBasename}}
+{{- range .Keys}}
+{{.Indent}}{{yaml .Key}}:{{end}}{{range .FieldRefs}}
+{{$.Indent}}{{yaml .Label}}: {{yaml .Ref}}{{end}}
+`, &helmValuesTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		FieldRefs:       refs,
+		Keys:            keys,
+		Indent:          strings.Repeat("  ", len(keys)),
+	})
+}
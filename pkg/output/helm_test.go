@@ -0,0 +1,109 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestHelmValuesDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	secretReference := &op.SecretReference{
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER", "DB_PASS"},
+	}
+	header := `# This file was generated by optruck.
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+# To restore, run the following command and pass the values with "-f secret-values.yaml":
+#   $ op inject -i secret-values.yaml.1password -o secret-values.yaml
+`
+
+	testCases := []struct {
+		name     string
+		keyPath  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "top level",
+			expected: header + `"DB_USER": "{{op://vault-id/item-id/DB_USER}}"
+"DB_PASS": "{{op://vault-id/item-id/DB_PASS}}"
+`,
+		},
+		{
+			name:    "nested",
+			keyPath: "app.secrets",
+			expected: header + `"app":
+  "secrets":
+    "DB_USER": "{{op://vault-id/item-id/DB_USER}}"
+    "DB_PASS": "{{op://vault-id/item-id/DB_PASS}}"
+`,
+		},
+		{
+			name:    "empty key",
+			keyPath: "app..secrets",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := &HelmValuesDest{
+				Path:    filepath.Join(tmpDir, DefaultHelmValuesOutputPath),
+				KeyPath: tc.keyPath,
+			}
+			err := writeDest(dest, secretReference)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Write() expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			assertFileContent(t, dest.Path, tc.expected)
+		})
+	}
+}
+
+func TestHelmValuesDestCheckValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "plain values",
+			secrets: map[string]string{"A": "p@ss word!#'$", "B": "日本語"},
+		},
+		{
+			name:    "double quote",
+			secrets: map[string]string{"A": "ok", "B": `say "hi"`},
+			wantErr: true,
+		},
+		{
+			name:    "backslash",
+			secrets: map[string]string{"A": `C:\path`},
+			wantErr: true,
+		},
+		{
+			name:    "line break",
+			secrets: map[string]string{"A": "-----BEGIN KEY-----\nabc"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&HelmValuesDest{}).CheckValues(tt.secrets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
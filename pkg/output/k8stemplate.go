@@ -1,9 +1,7 @@
 package output
 
 import (
-	"os"
 	"path/filepath"
-	"text/template"
 
 	"github.com/yammerjp/optruck/pkg/op"
 )
@@ -12,6 +10,7 @@ type K8sSecretTemplateDest struct {
 	Path       string
 	Namespace  string
 	SecretName string
+	op.RefOptions
 }
 
 func (d *K8sSecretTemplateDest) GetPath() string {
@@ -20,40 +19,34 @@ func (d *K8sSecretTemplateDest) GetPath() string {
 
 type k8sTemplateData struct {
 	*op.SecretReference
-	Dest *K8sSecretTemplateDest
+	Dest      *K8sSecretTemplateDest
+	FieldRefs []op.FieldRef
 }
 
 func (d *K8sSecretTemplateDest) GetBasename() string {
 	return filepath.Base(d.Path)
 }
 
-func (d *K8sSecretTemplateDest) Write(secretReference *op.SecretReference) error {
-	file, err := os.Create(d.Path)
+func (d *K8sSecretTemplateDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
 
-	tmpl, err := template.New("k8s-secret").Parse(`# This file was generated by optruck.{{if .SecretReference.Account}}
-#   - 1password account: {{.SecretReference.Account}}{{end}}{{if .SecretReference.VaultName}}
-#   - 1password vault: {{.SecretReference.VaultName}}{{end}}
+	return renderTemplate(d.Path, "k8s-secret", `{{template "header" .}}
 # To restore, run the following command:
-#   $ op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}| kubectl apply -f -
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}| kubectl apply -f -
 apiVersion: v1
 kind: Secret
 metadata:
   name: {{.Dest.SecretName}}
   namespace: {{.Dest.Namespace}}
 type: Opaque
-data:{{range .SecretReference.GetFieldRefs}}
+data:{{range .FieldRefs}}
   {{.Label}}: {{.Ref}}{{end}}
-`)
-	if err != nil {
-		return err
-	}
-
-	return tmpl.Execute(file, k8sTemplateData{
+`, k8sTemplateData{
 		SecretReference: secretReference,
 		Dest:            d,
+		FieldRefs:       refs,
 	})
 }
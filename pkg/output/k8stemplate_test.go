@@ -40,6 +40,7 @@ func TestK8sSecretTemplateDestWrite(t *testing.T) {
 			expected: `# This file was generated by optruck.
 #   - 1password account: test.1password.com
 #   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
 # To restore, run the following command:
 #   $ op inject -i test1.yaml --account test.1password.com | kubectl apply -f -
 apiVersion: v1
@@ -69,6 +70,7 @@ data:
 			},
 			expected: `# This file was generated by optruck.
 #   - 1password vault: TestVault
+#   - 1password item: op://TestVault/APISecret (op://vault-id/item-id)
 # To restore, run the following command:
 #   $ op inject -i test2.yaml | kubectl apply -f -
 apiVersion: v1
@@ -79,6 +81,37 @@ metadata:
 type: Opaque
 data:
   API_KEY: {{op://vault-id/item-id/API_KEY}}
+`,
+		},
+		{
+			name: "keys with special characters",
+			dest: &K8sSecretTemplateDest{
+				Path:       filepath.Join(tmpDir, "test3.yaml"),
+				Namespace:  "default",
+				SecretName: "tls",
+			},
+			resp: &op.SecretReference{
+				VaultName:   "TestVault",
+				VaultID:     "vault-id",
+				ItemName:    "tls",
+				ItemID:      "item-id",
+				FieldLabels: []string{"tls.crt", "tls.key"},
+				FieldIDs:    []string{"tls_crt-8d0fcdc3", "tls_key-2ca6a1a4"},
+			},
+			expected: `# This file was generated by optruck.
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/tls (op://vault-id/item-id)
+# To restore, run the following command:
+#   $ op inject -i test3.yaml | kubectl apply -f -
+apiVersion: v1
+kind: Secret
+metadata:
+  name: tls
+  namespace: default
+type: Opaque
+data:
+  tls.crt: {{op://vault-id/item-id/tls_crt-8d0fcdc3}}
+  tls.key: {{op://vault-id/item-id/tls_key-2ca6a1a4}}
 `,
 		},
 	}
@@ -86,7 +119,7 @@ data:
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			// Write the template
-			err := tc.dest.Write(tc.resp)
+			err := writeDest(tc.dest, tc.resp)
 			if err != nil {
 				t.Fatalf("Write() error = %v", err)
 			}
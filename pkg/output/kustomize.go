@@ -0,0 +1,73 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+const DefaultKustomizeOutputPath = "kustomization.yaml"
+
+// KustomizeDest writes a kustomization.yaml and the template of its EnvFile.
+type KustomizeDest struct {
+	Path       string
+	Namespace  string
+	SecretName string
+	EnvFile    string
+	op.RefOptions
+}
+
+func DefaultKustomizeEnvFile(secretName string) string {
+	return fmt.Sprintf("%s.env", secretName)
+}
+
+func (d *KustomizeDest) GetPath() string {
+	return d.Path
+}
+
+func (d *KustomizeDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+// EnvTemplatePath is the path of the env file template. Like kustomize,
+// EnvFile is relative to the directory of the kustomization.
+func (d *KustomizeDest) EnvTemplatePath() string {
+	return envTemplatePath(d.Path, d.EnvFile)
+}
+
+type kustomizeTemplateData struct {
+	*op.SecretReference
+	Dest      *KustomizeDest
+	FieldRefs []op.FieldRef
+}
+
+func (d *KustomizeDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+	data := &kustomizeTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		FieldRefs:       refs,
+	}
+
+	envFile, err := renderTemplate(d.EnvTemplatePath(), "kustomize-env-file", envFileTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	kustomization, err := renderTemplate(d.Path, "kustomize", `{{template "header" .}}
+# The secrets are read from {{.Dest.EnvFile}}. Before "kustomize build", run the following command:
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.EnvFile}}.1password {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o {{.Dest.EnvFile}}
+secretGenerator:
+  - name: {{.Dest.SecretName}}
+    namespace: {{.Dest.Namespace}}
+    envs:
+      - {{yaml .Dest.EnvFile}}
+`, data)
+	if err != nil {
+		return nil, err
+	}
+	return append(envFile, kustomization...), nil
+}
@@ -0,0 +1,49 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestKustomizeDestWrite(t *testing.T) {
+	dir := t.TempDir()
+	dest := &KustomizeDest{
+		Path:       filepath.Join(dir, DefaultKustomizeOutputPath),
+		Namespace:  "test-namespace",
+		SecretName: "test-secret",
+		EnvFile:    DefaultKustomizeEnvFile("test-secret"),
+	}
+	err := writeDest(dest, &op.SecretReference{
+		Account:     "test.1password.com",
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER", "tls.crt"},
+		FieldIDs:    []string{"DB_USER", "tls_crt-8d0fcdc3"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	header := `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+`
+	assertFileContent(t, dest.Path, header+`# The secrets are read from test-secret.env. Before "kustomize build", run the following command:
+#   $ op inject -i test-secret.env.1password --account test.1password.com -o test-secret.env
+secretGenerator:
+  - name: test-secret
+    namespace: test-namespace
+    envs:
+      - "test-secret.env"
+`)
+	assertFileContent(t, filepath.Join(dir, "test-secret.env.1password"), header+`# To restore, run the following command:
+#   $ op inject -i test-secret.env.1password --account test.1password.com -o test-secret.env
+DB_USER={{op://vault-id/item-id/DB_USER}}
+tls.crt={{op://vault-id/item-id/tls_crt-8d0fcdc3}}
+`)
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// OnePasswordItemDest writes a OnePasswordItem for the 1Password Operator.
+type OnePasswordItemDest struct {
+	Path       string
+	Namespace  string
+	SecretName string
+	op.RefOptions
+}
+
+func DefaultOnePasswordItemOutputPath(secretName string) string {
+	return fmt.Sprintf("%s-onepassworditem.yaml", secretName)
+}
+
+func (d *OnePasswordItemDest) GetPath() string {
+	return d.Path
+}
+
+func (d *OnePasswordItemDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+type onePasswordItemTemplateData struct {
+	*op.SecretReference
+	Dest     *OnePasswordItemDest
+	ItemPath string
+}
+
+func (d *OnePasswordItemDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	itemPath, err := secretReference.GetItemPath(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "onepassword-item", `{{template "header" .}}
+# The 1Password Operator creates the Secret {{.Dest.Namespace}}/{{.Dest.SecretName}} from the item.
+# To apply, run the following command:
+{{- if .Dest.VaultVar}}
+#   $ {{template "vault-var" .}}envsubst < {{.Dest.GetBasename}} | kubectl apply -f -
+{{- else}}
+#   $ kubectl apply -f {{.Dest.GetBasename}}
+{{- end}}
+apiVersion: onepassword.com/v1
+kind: OnePasswordItem
+metadata:
+  name: {{.Dest.SecretName}}
+  namespace: {{.Dest.Namespace}}
+spec:
+  itemPath: {{yaml .ItemPath}}
+`, &onePasswordItemTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		ItemPath:        itemPath,
+	})
+}
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestOnePasswordItemDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	secretReference := &op.SecretReference{
+		Account:     "test.1password.com",
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER", "DB_PASS"},
+	}
+
+	testCases := []struct {
+		name       string
+		refOptions op.RefOptions
+		expected   string
+	}{
+		{
+			name: "item ID",
+			expected: `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+# The 1Password Operator creates the Secret test-namespace/test-secret from the item.
+# To apply, run the following command:
+#   $ kubectl apply -f test-secret-onepassworditem.yaml
+apiVersion: onepassword.com/v1
+kind: OnePasswordItem
+metadata:
+  name: test-secret
+  namespace: test-namespace
+spec:
+  itemPath: "vaults/vault-id/items/item-id"
+`,
+		},
+		{
+			name:       "vault variable",
+			refOptions: op.RefOptions{VaultVar: "APP_ENV"},
+			expected: `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: ${APP_ENV} (e.g. TestVault)
+#   - 1password item: op://${APP_ENV}/TestItem
+# The 1Password Operator creates the Secret test-namespace/test-secret from the item.
+# To apply, run the following command:
+#   $ APP_ENV=TestVault envsubst < test-secret-onepassworditem.yaml | kubectl apply -f -
+apiVersion: onepassword.com/v1
+kind: OnePasswordItem
+metadata:
+  name: test-secret
+  namespace: test-namespace
+spec:
+  itemPath: "vaults/${APP_ENV}/items/TestItem"
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := &OnePasswordItemDest{
+				Path:       filepath.Join(tmpDir, DefaultOnePasswordItemOutputPath("test-secret")),
+				Namespace:  "test-namespace",
+				SecretName: "test-secret",
+				RefOptions: tc.refOptions,
+			}
+			if err := writeDest(dest, secretReference); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			assertFileContent(t, dest.Path, tc.expected)
+		})
+	}
+}
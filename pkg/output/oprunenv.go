@@ -0,0 +1,46 @@
+package output
+
+import (
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// OpRunEnvDest writes an env file for `op run --env-file`, which takes bare
+// `op://` references instead of the `{{op://...}}` ones of `op inject`, so the
+// secrets never have to be written to disk in plaintext.
+type OpRunEnvDest struct {
+	Path string
+	op.RefOptions
+}
+
+func (d *OpRunEnvDest) GetPath() string {
+	return d.Path
+}
+
+func (d *OpRunEnvDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+type opRunEnvTemplateData struct {
+	*op.SecretReference
+	Dest      *OpRunEnvDest
+	FieldRefs []op.FieldRef
+}
+
+func (d *OpRunEnvDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "op-run-env", `{{template "header" .}}
+# To run a command with the secrets, run the following command:
+#   $ {{template "vault-var" .}}op run --env-file {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-- <command>{{range .FieldRefs}}
+{{.Label}}={{.URI}}{{end}}
+`, &opRunEnvTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		FieldRefs:       refs,
+	})
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestOpRunEnvDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testCases := []struct {
+		name            string
+		dest            *OpRunEnvDest
+		secretReference *op.SecretReference
+		expected        string
+	}{
+		{
+			name: "basic case",
+			dest: &OpRunEnvDest{
+				Path: filepath.Join(tmpDir, ".env.1password"),
+			},
+			secretReference: &op.SecretReference{
+				Account:     "test.1password.com",
+				VaultName:   "TestVault",
+				VaultID:     "vault-id",
+				ItemName:    "TestItem",
+				ItemID:      "item-id",
+				FieldLabels: []string{"DB_USER", "DB_PASS"},
+			},
+			expected: `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+# To run a command with the secrets, run the following command:
+#   $ op run --env-file .env.1password --account test.1password.com -- <command>
+DB_USER=op://vault-id/item-id/DB_USER
+DB_PASS=op://vault-id/item-id/DB_PASS
+`,
+		},
+		{
+			name: "vault variable",
+			dest: &OpRunEnvDest{
+				Path:       filepath.Join(tmpDir, "app.env"),
+				RefOptions: op.RefOptions{VaultVar: "APP_ENV"},
+			},
+			secretReference: &op.SecretReference{
+				VaultName:   "dev",
+				VaultID:     "vault-id",
+				ItemName:    "my-app",
+				ItemID:      "item-id",
+				FieldLabels: []string{"API_KEY"},
+			},
+			expected: `# This file was generated by optruck.
+#   - 1password vault: ${APP_ENV} (e.g. dev)
+#   - 1password item: op://${APP_ENV}/my-app
+# To run a command with the secrets, run the following command:
+#   $ APP_ENV=dev op run --env-file app.env -- <command>
+API_KEY=op://${APP_ENV}/my-app/API_KEY
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := writeDest(tc.dest, tc.secretReference); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			content, err := os.ReadFile(tc.dest.Path)
+			if err != nil {
+				t.Fatalf("failed to read generated file: %v", err)
+			}
+			if got := string(content); got != tc.expected {
+				t.Errorf("Write() generated content mismatch\nwant:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
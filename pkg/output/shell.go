@@ -0,0 +1,61 @@
+package output
+
+import (
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// ShellDest writes a shell script of `export KEY='{{op://...}}'` lines, to be
+// restored by `op inject` and sourced. The values are single-quoted, so that
+// the shell does not expand them, and CheckValues rejects a value with a
+// single quote, which would end the quote and run the rest as commands.
+type ShellDest struct {
+	Path string
+	op.RefOptions
+}
+
+const DefaultShellOutputPath = "env.sh.1password"
+
+func (d *ShellDest) GetPath() string {
+	return d.Path
+}
+
+func (d *ShellDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+func (d *ShellDest) RestoredBasename() string {
+	return restoredBasename(d.Path, "env.sh")
+}
+
+type shellTemplateData struct {
+	*op.SecretReference
+	Dest      *ShellDest
+	FieldRefs []op.FieldRef
+}
+
+func (d *ShellDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEnvNames(refs); err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "shell", `{{template "header" .}}
+# To restore, run the following commands:
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o {{.Dest.RestoredBasename}}
+#   $ . ./{{.Dest.RestoredBasename}}{{range .FieldRefs}}
+export {{.Label}}='{{.Ref}}'{{end}}
+`, &shellTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		FieldRefs:       refs,
+	})
+}
+
+func (d *ShellDest) CheckValues(secrets map[string]string) error {
+	return checkSingleQuotable(secrets, "shell")
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestShellDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("basic case", func(t *testing.T) {
+		dest := &ShellDest{Path: filepath.Join(tmpDir, DefaultShellOutputPath)}
+		err := writeDest(dest, &op.SecretReference{
+			Account:     "test.1password.com",
+			VaultName:   "TestVault",
+			VaultID:     "vault-id",
+			ItemName:    "TestItem",
+			ItemID:      "item-id",
+			FieldLabels: []string{"DB_USER", "DB_PASS"},
+		})
+		if err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		assertFileContent(t, dest.Path, `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+# To restore, run the following commands:
+#   $ op inject -i env.sh.1password --account test.1password.com -o env.sh
+#   $ . ./env.sh
+export DB_USER='{{op://vault-id/item-id/DB_USER}}'
+export DB_PASS='{{op://vault-id/item-id/DB_PASS}}'
+`)
+	})
+
+	t.Run("invalid variable name", func(t *testing.T) {
+		dest := &ShellDest{Path: filepath.Join(tmpDir, "invalid.sh.1password")}
+		err := writeDest(dest, &op.SecretReference{
+			VaultID:     "vault-id",
+			ItemID:      "item-id",
+			FieldLabels: []string{"tls.crt"},
+			FieldIDs:    []string{"tls_crt-8d0fcdc3"},
+		})
+		if err == nil {
+			t.Error("Write() expected error but got nil")
+		}
+	})
+}
+
+func TestShellDestCheckValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "values the shell would expand",
+			secrets: map[string]string{"A": "$HOME `id` \"x\" \\n", "B": "line1\nline2"},
+		},
+		{
+			name:    "single quote",
+			secrets: map[string]string{"A": "ok", "B": "it's'; rm -rf ~; '"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&ShellDest{}).CheckValues(tt.secrets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
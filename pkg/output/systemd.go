@@ -0,0 +1,59 @@
+package output
+
+import (
+	"path/filepath"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// SystemdDest writes a file for systemd's EnvironmentFile=, to be restored by
+// `op inject`. The values are single-quoted, and CheckValues rejects a value
+// with a single quote, which would end the quote.
+type SystemdDest struct {
+	Path string
+	op.RefOptions
+}
+
+const DefaultSystemdOutputPath = "systemd.env.1password"
+
+func (d *SystemdDest) GetPath() string {
+	return d.Path
+}
+
+func (d *SystemdDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+func (d *SystemdDest) RestoredBasename() string {
+	return restoredBasename(d.Path, "systemd.env")
+}
+
+type systemdTemplateData struct {
+	*op.SecretReference
+	Dest      *SystemdDest
+	FieldRefs []op.FieldRef
+}
+
+func (d *SystemdDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEnvNames(refs); err != nil {
+		return nil, err
+	}
+
+	return renderTemplate(d.Path, "systemd", `{{template "header" .}}
+# To restore, run the following command and set EnvironmentFile=<absolute path of {{.Dest.RestoredBasename}}> in the unit:
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.GetBasename}} {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o {{.Dest.RestoredBasename}}{{range .FieldRefs}}
+{{.Label}}='{{.Ref}}'{{end}}
+`, &systemdTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		FieldRefs:       refs,
+	})
+}
+
+func (d *SystemdDest) CheckValues(secrets map[string]string) error {
+	return checkSingleQuotable(secrets, "systemd")
+}
@@ -0,0 +1,41 @@
+package output
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestSystemdDestWrite(t *testing.T) {
+	dest := &SystemdDest{
+		Path:       filepath.Join(t.TempDir(), "app.env.1password"),
+		RefOptions: op.RefOptions{VaultVar: "APP_ENV"},
+	}
+	err := writeDest(dest, &op.SecretReference{
+		VaultName:   "prod",
+		VaultID:     "vault-id",
+		ItemName:    "my-app",
+		ItemID:      "item-id",
+		FieldLabels: []string{"API_KEY"},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	assertFileContent(t, dest.Path, `# This file was generated by optruck.
+#   - 1password vault: ${APP_ENV} (e.g. prod)
+#   - 1password item: op://${APP_ENV}/my-app
+# To restore, run the following command and set EnvironmentFile=<absolute path of app.env> in the unit:
+#   $ APP_ENV=prod op inject -i app.env.1password -o app.env
+API_KEY='{{op://${APP_ENV}/my-app/API_KEY}}'
+`)
+}
+
+func TestSystemdDestCheckValues(t *testing.T) {
+	if err := (&SystemdDest{}).CheckValues(map[string]string{"A": "p@ss $word"}); err != nil {
+		t.Errorf("CheckValues() error = %v", err)
+	}
+	if err := (&SystemdDest{}).CheckValues(map[string]string{"A": "it's"}); err == nil {
+		t.Error("CheckValues() accepted a single quote")
+	}
+}
@@ -0,0 +1,142 @@
+package output
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// headerTemplate is shared by the templates so that every generated file
+// tells which account, vault and item it points to, both by name and by ID.
+// "vault-var" prefixes the restore command with the vault variable, if any.
+const headerTemplate = `{{define "header"}}# This file was generated by optruck.{{if .SecretReference.Account}}
+#   - 1password account: {{.SecretReference.Account}}{{end}}{{if .Dest.VaultVar}}
+#   - 1password vault: ${{"{"}}{{.Dest.VaultVar}}{{"}"}} (e.g. {{.SecretReference.VaultName}})
+#   - 1password item: op://${{"{"}}{{.Dest.VaultVar}}{{"}"}}/{{.SecretReference.ItemName}}{{else}}{{if .SecretReference.VaultName}}
+#   - 1password vault: {{.SecretReference.VaultName}}{{end}}
+#   - 1password item: op://{{.SecretReference.VaultName}}/{{.SecretReference.ItemName}} (op://{{.SecretReference.VaultID}}/{{.SecretReference.ItemID}}){{end}}{{end}}
+{{- define "vault-var"}}{{if .Dest.VaultVar}}{{.Dest.VaultVar}}={{.SecretReference.VaultName}} {{end}}{{end}}`
+
+// envFileTemplate is the template of an env file that another file (e.g. a
+// compose override) points at by .Dest.EnvFile, relative to its directory.
+const envFileTemplate = `{{template "header" .}}
+# To restore, run the following command:
+#   $ {{template "vault-var" .}}op inject -i {{.Dest.EnvFile}}.1password {{if .SecretReference.Account}}--account {{.SecretReference.Account}} {{end}}-o {{.Dest.EnvFile}}{{range .FieldRefs}}
+{{.Label}}={{.Ref}}{{end}}
+`
+
+var templateFuncs = template.FuncMap{
+	"quote":  strconv.Quote,
+	"yaml":   yamlQuote,
+	"base64": base64Encode,
+	"upper":  strings.ToUpper,
+}
+
+// yamlQuote returns s as a double-quoted YAML scalar. A JSON string is also a
+// valid YAML one.
+func yamlQuote(s string) (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// restoredBasename is the name of the file a template at path is restored to
+// by `op inject`, i.e. its basename without ".1password".
+func restoredBasename(path, fallback string) string {
+	if name, ok := strings.CutSuffix(filepath.Base(path), ".1password"); ok && name != "" {
+		return name
+	}
+	return fallback
+}
+
+// envTemplatePath is the path of the template of envFile, which is relative
+// to the directory of the file at path pointing at it.
+func envTemplatePath(path, envFile string) string {
+	if !filepath.IsAbs(envFile) {
+		envFile = filepath.Join(filepath.Dir(path), envFile)
+	}
+	return envFile + ".1password"
+}
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateEnvNames checks that the labels can be used as variable names of a
+// shell or a systemd unit.
+func validateEnvNames(refs []op.FieldRef) error {
+	for _, ref := range refs {
+		if !envNameRegex.MatchString(ref.Label) {
+			return fmt.Errorf("%q is not a valid environment variable name, it must consist of letters, digits and '_' and must not start with a digit", ref.Label)
+		}
+	}
+	return nil
+}
+
+// checkSingleQuotable checks that the secrets can be single-quoted, which
+// takes every character but the quote itself.
+func checkSingleQuotable(secrets map[string]string, format string) error {
+	for _, key := range slices.Sorted(maps.Keys(secrets)) {
+		if strings.Contains(secrets[key], "'") {
+			return fmt.Errorf("the value of %s contains a single quote, which would end the single-quoted value in the %s file, please use another format such as env", key, format)
+		}
+	}
+	return nil
+}
+
+// checkValueChars checks that no secret holds a character reject reports,
+// telling why the restored file would not hold it as is.
+func checkValueChars(secrets map[string]string, reject func(r rune) bool, reason string) error {
+	for _, key := range slices.Sorted(maps.Keys(secrets)) {
+		if i := strings.IndexFunc(secrets[key], reject); i >= 0 {
+			r, _ := utf8.DecodeRuneInString(secrets[key][i:])
+			return fmt.Errorf("the value of %s contains %q, which %s, please use another format such as env", key, r, reason)
+		}
+	}
+	return nil
+}
+
+// breaksDoubleQuotedYAML reports the characters `op inject` cannot put as is
+// in a double-quoted YAML value: `"` ends it, `\` starts an escape, and a
+// control character such as a line break is not kept.
+func breaksDoubleQuotedYAML(r rune) bool {
+	return r == '"' || r == '\\' || unicode.IsControl(r)
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Parse(headerTemplate + text)
+}
+
+func renderTemplate(path, name, text string, data any) ([]File, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return nil, err
+	}
+	return executeTemplate(path, tmpl, data)
+}
+
+// executeTemplate renders the template in memory, so that a failing template
+// never leaves a truncated file behind.
+func executeTemplate(path string, tmpl *template.Template, data any) ([]File, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return []File{{Path: path, Content: buf.Bytes()}}, nil
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// TerraformDest writes a onepassword_item data source and a map of its fields.
+type TerraformDest struct {
+	Path string
+	op.RefOptions
+}
+
+var ErrTerraformVaultVar = errors.New("the terraform format cannot reference the vault by a variable, since the provider looks up the vault by ID")
+
+var terraformInvalidNameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// TerraformName converts the item name to a Terraform identifier, e.g.
+// "My App" -> "my_app".
+func TerraformName(itemName string) string {
+	name := strings.Trim(terraformInvalidNameChars.ReplaceAllString(strings.ToLower(itemName), "_"), "_")
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		name = "item_" + name
+	}
+	return name
+}
+
+func DefaultTerraformOutputPath(itemName string) string {
+	return fmt.Sprintf("onepassword_%s.tf", TerraformName(itemName))
+}
+
+func (d *TerraformDest) GetPath() string {
+	return d.Path
+}
+
+func (d *TerraformDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+// hclQuote returns s as an HCL string literal, escaping the template
+// sequences "${" and "%{".
+func hclQuote(s string) string {
+	s = strconv.Quote(s)
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+type terraformTemplateData struct {
+	*op.SecretReference
+	Dest *TerraformDest
+	Name string
+	// Lookup is the attribute selecting the item, uuid or title
+	Lookup string
+	Vault  string
+	Item   string
+	Fields []string
+}
+
+func (d *TerraformDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	if d.VaultVar != "" {
+		return nil, ErrTerraformVaultVar
+	}
+	data := &terraformTemplateData{
+		SecretReference: secretReference,
+		Dest:            d,
+		Name:            TerraformName(secretReference.ItemName),
+		Lookup:          "uuid",
+		Vault:           hclQuote(secretReference.VaultID),
+		Item:            hclQuote(secretReference.ItemID),
+	}
+	if d.RefStyle == op.RefStyleName {
+		data.Lookup = "title"
+		data.Item = hclQuote(secretReference.ItemName)
+	}
+	for _, label := range secretReference.FieldLabels {
+		data.Fields = append(data.Fields, hclQuote(label))
+	}
+
+	// the provider only reads the fields of a section, see op.FieldSection
+	return renderTemplate(d.Path, "terraform", `{{template "header" .}}
+# Requires the 1Password provider (https://registry.terraform.io/providers/1Password/onepassword).
+# Use the fields as local.{{.Name}}["<label>"].
+data "onepassword_item" "{{.Name}}" {
+  vault = {{.Vault}}
+  {{printf "%-5s" .Lookup}} = {{.Item}}
+}
+
+locals {
+  {{.Name}}_fields = {
+    for field in flatten(data.onepassword_item.{{.Name}}.section[*].field) : field.label => field.value
+  }
+  {{.Name}} = {
+{{- range .Fields}}
+    {{.}} = local.{{$.Name}}_fields[{{.}}]
+{{- end}}
+  }
+}
+`, data)
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestTerraformName(t *testing.T) {
+	tests := map[string]string{
+		"my-app":        "my_app",
+		"My App (prod)": "my_app_prod",
+		"2fa":           "item_2fa",
+		"日本語":           "item_",
+	}
+	for in, want := range tests {
+		if got := TerraformName(in); got != want {
+			t.Errorf("TerraformName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTerraformDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	secretReference := &op.SecretReference{
+		Account:     "test.1password.com",
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "my-app",
+		ItemID:      "item-id",
+		FieldLabels: []string{"DB_USER", "tls.crt"},
+	}
+	header := `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/my-app (op://vault-id/item-id)
+# Requires the 1Password provider (https://registry.terraform.io/providers/1Password/onepassword).
+# Use the fields as local.my_app["<label>"].
+`
+	locals := `
+locals {
+  my_app_fields = {
+    for field in flatten(data.onepassword_item.my_app.section[*].field) : field.label => field.value
+  }
+  my_app = {
+    "DB_USER" = local.my_app_fields["DB_USER"]
+    "tls.crt" = local.my_app_fields["tls.crt"]
+  }
+}
+`
+
+	testCases := []struct {
+		name       string
+		refOptions op.RefOptions
+		expected   string
+		wantErr    error
+	}{
+		{
+			name: "item ID",
+			expected: header + `data "onepassword_item" "my_app" {
+  vault = "vault-id"
+  uuid  = "item-id"
+}
+` + locals,
+		},
+		{
+			name:       "item name",
+			refOptions: op.RefOptions{RefStyle: op.RefStyleName},
+			expected: header + `data "onepassword_item" "my_app" {
+  vault = "vault-id"
+  title = "my-app"
+}
+` + locals,
+		},
+		{
+			name:       "vault variable",
+			refOptions: op.RefOptions{VaultVar: "APP_ENV"},
+			wantErr:    ErrTerraformVaultVar,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := &TerraformDest{
+				Path:       filepath.Join(tmpDir, DefaultTerraformOutputPath(secretReference.ItemName)),
+				RefOptions: tc.refOptions,
+			}
+			err := writeDest(dest, secretReference)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil {
+				assertFileContent(t, dest.Path, tc.expected)
+			}
+		})
+	}
+}
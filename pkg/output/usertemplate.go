@@ -0,0 +1,115 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+// UserTemplateDest renders a user-supplied text/template file, so that teams
+// can produce formats optruck has no built-in Dest for.
+//
+// The template is executed against UserTemplateData:
+//
+//	.Account                     1Password account (empty for service accounts)
+//	.Vault.Name, .Vault.ID       1Password vault
+//	.Item.Name, .Item.ID         1Password item
+//	.Fields                      fields of the item, each with
+//	                               .Label  key in the data source (e.g. DB_PASSWORD)
+//	                               .Ref    reference for `op inject` ({{op://...}})
+//	                               .URI    reference for `op run` and `op read` (op://...)
+//	.Source.Type                 "env-file", "k8s-secret", "compose", "shell" or "systemd"
+//	.Source.Path                 path of the source file
+//	.Source.Namespace, .Source.SecretName
+//	                             Kubernetes Secret
+//	.Source.Service              service in the compose file
+//	.Dest.Path, .Dest.Basename   path of the generated file
+//	.Header                      comment lines ("# ...") describing the item
+//
+// and can use the functions quote (Go string literal), yaml (double-quoted
+// YAML scalar), base64 and upper.
+type UserTemplateDest struct {
+	Path         string
+	TemplatePath string
+	Source       SourceMetadata
+	op.RefOptions
+}
+
+type SourceMetadata struct {
+	Type       string
+	Path       string
+	Namespace  string
+	SecretName string
+	Service    string
+}
+
+type UserTemplateData struct {
+	Account string
+	Vault   UserTemplateObject
+	Item    UserTemplateObject
+	Fields  []op.FieldRef
+	Source  SourceMetadata
+	Dest    UserTemplateDestData
+	Header  string
+}
+
+type UserTemplateObject struct {
+	Name string
+	ID   string
+}
+
+type UserTemplateDestData struct {
+	Path     string
+	Basename string
+}
+
+func (d *UserTemplateDest) GetPath() string {
+	return d.Path
+}
+
+func (d *UserTemplateDest) GetBasename() string {
+	return filepath.Base(d.Path)
+}
+
+// DefaultUserTemplateOutputPath derives the output path from the template
+// path, e.g. "values.yaml.tmpl" -> "values.yaml.1password".
+func DefaultUserTemplateOutputPath(templatePath string) string {
+	return strings.TrimSuffix(filepath.Base(templatePath), ".tmpl") + ".1password"
+}
+
+func (d *UserTemplateDest) Render(secretReference *op.SecretReference) ([]File, error) {
+	refs, err := secretReference.GetFieldRefsFor(d.RefOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := os.ReadFile(d.TemplatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the template file: %w", err)
+	}
+	tmpl, err := parseTemplate(filepath.Base(d.TemplatePath), string(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the template file %s: %w", d.TemplatePath, err)
+	}
+
+	header := &strings.Builder{}
+	if err := tmpl.ExecuteTemplate(header, "header", struct {
+		SecretReference *op.SecretReference
+		Dest            *UserTemplateDest
+	}{secretReference, d}); err != nil {
+		return nil, err
+	}
+
+	return executeTemplate(d.Path, tmpl, &UserTemplateData{
+		Account: secretReference.Account,
+		Vault:   UserTemplateObject{Name: secretReference.VaultName, ID: secretReference.VaultID},
+		Item:    UserTemplateObject{Name: secretReference.ItemName, ID: secretReference.ItemID},
+		Fields:  refs,
+		Source:  d.Source,
+		Dest:    UserTemplateDestData{Path: d.Path, Basename: d.GetBasename()},
+		Header:  header.String(),
+	})
+}
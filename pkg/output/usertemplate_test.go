@@ -0,0 +1,111 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yammerjp/optruck/pkg/op"
+)
+
+func TestUserTemplateDestWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	secretReference := &op.SecretReference{
+		Account:     "test.1password.com",
+		VaultName:   "TestVault",
+		VaultID:     "vault-id",
+		ItemName:    "TestItem",
+		ItemID:      "item-id",
+		FieldLabels: []string{"db_user", "tls.crt"},
+		FieldIDs:    []string{"db_user", "tls_crt-8d0fcdc3"},
+	}
+
+	testCases := []struct {
+		name     string
+		template string
+		source   SourceMetadata
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "json with header",
+			template: `{{.Header}}
+# $ op inject -i {{.Dest.Basename}} -o config.json
+{
+{{- range $i, $f := .Fields}}{{if $i}},{{end}}
+  {{quote $f.Label}}: {{quote $f.Ref}}
+{{- end}}
+}
+`,
+			expected: `# This file was generated by optruck.
+#   - 1password account: test.1password.com
+#   - 1password vault: TestVault
+#   - 1password item: op://TestVault/TestItem (op://vault-id/item-id)
+# $ op inject -i out.1password -o config.json
+{
+  "db_user": "{{op://vault-id/item-id/db_user}}",
+  "tls.crt": "{{op://vault-id/item-id/tls_crt-8d0fcdc3}}"
+}
+`,
+		},
+		{
+			name: "metadata and functions",
+			template: `# {{.Source.Type}} {{.Source.Namespace}}/{{.Source.SecretName}} -> {{.Account}} {{.Vault.Name}}({{.Vault.ID}}) {{.Item.Name}}({{.Item.ID}})
+{{range .Fields}}{{upper .Label}}: {{yaml .URI}} {{base64 .Label}}
+{{end}}`,
+			source: SourceMetadata{Type: "k8s-secret", Namespace: "default", SecretName: "app"},
+			expected: `# k8s-secret default/app -> test.1password.com TestVault(vault-id) TestItem(item-id)
+DB_USER: "op://vault-id/item-id/db_user" ZGJfdXNlcg==
+TLS.CRT: "op://vault-id/item-id/tls_crt-8d0fcdc3" dGxzLmNydA==
+`,
+		},
+		{
+			name:     "parse error",
+			template: `{{range .Fields}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown data",
+			template: `{{.Unknown}}`,
+			wantErr:  true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(tmpDir, string(rune('a'+i)))
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			templatePath := filepath.Join(dir, "out.tmpl")
+			if err := os.WriteFile(templatePath, []byte(tc.template), 0644); err != nil {
+				t.Fatal(err)
+			}
+			dest := &UserTemplateDest{
+				Path:         filepath.Join(dir, DefaultUserTemplateOutputPath(templatePath)),
+				TemplatePath: templatePath,
+				Source:       tc.source,
+			}
+
+			err := writeDest(dest, secretReference)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Write() expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			content, err := os.ReadFile(dest.Path)
+			if err != nil {
+				t.Fatalf("failed to read generated file: %v", err)
+			}
+			if got := string(content); got != tc.expected {
+				t.Errorf("Write() generated content mismatch\nwant:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}